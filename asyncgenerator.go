@@ -0,0 +1,54 @@
+package futures
+
+import (
+	"context"
+)
+
+// AsyncGeneratorFunc specifies the function signature for each step of a Generator created with NewAsyncGenerator.
+// Since steps are executed ahead of time they do not receive the result of the previous step and instead receive the argument of the factory function along with the index of the step starting at 0.
+type AsyncGeneratorFunc func(argv interface{}, index int) (interface{}, bool, error)
+
+// asyncStep is the result of an AsyncGeneratorFunc execution
+type asyncStep struct {
+	value interface{}
+	done  bool
+}
+
+// NewAsyncGenerator returns a factory method for creating Generator's whose steps are executed on the provided WorkerPool with up to lookahead steps in flight at a time, counting the step that is yielded next.
+// Results are yielded in the order of the steps and the results of steps executed after the step that finished the Generator are discarded. Values sent to the Generator with Send or Throw are ignored.
+func NewAsyncGenerator(fn AsyncGeneratorFunc, wp WorkerPoolInterface, lookahead int) func(interface{}) Generator {
+	if lookahead < 1 {
+		lookahead = 1
+	}
+	return func(argv interface{}) Generator {
+		var pending []chan Value
+		index := 0
+		finished := false
+		return generate(context.Background(), func(context.Context, interface{}) (interface{}, bool, error) {
+			for !finished && len(pending) < lookahead {
+				i := index
+				index++
+				out := make(chan Value, 1)
+				if !wp.Do(out, func() (interface{}, error) {
+					value, done, err := fn(argv, i)
+					return asyncStep{value, done}, err
+				}) {
+					out <- Value{Data: asyncStep{done: true}, Error: ClosedWorkerPoolError{}}
+					finished = true
+				}
+				pending = append(pending, out)
+			}
+			v := <-pending[0]
+			pending = pending[1:]
+			step, ok := v.Data.(asyncStep)
+			if !ok {
+				return nil, false, v.Error
+			}
+			if step.done {
+				finished = true
+				pending = nil
+			}
+			return step.value, step.done, v.Error
+		}, argv, nil)
+	}
+}
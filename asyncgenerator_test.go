@@ -0,0 +1,45 @@
+package futures
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAsyncGenerator(t *testing.T) {
+	wp := NewFuturesWorkerPool(4)
+	defer wp.Close()
+
+	var running, peak, calls int32
+	factory := NewAsyncGenerator(func(argv interface{}, index int) (interface{}, bool, error) {
+		atomic.AddInt32(&calls, 1)
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(time.Duration(5-index%5) * time.Millisecond)
+		return argv.(string) + string(rune('a'+index)), index == 5, nil
+	}, wp, 3)
+
+	var results []interface{}
+	for v := range factory("page-") {
+		results = append(results, v.Value)
+	}
+	assert.Equal(t, []interface{}{"page-a", "page-b", "page-c", "page-d", "page-e", "page-f"}, results, "should yield the results of each step in order")
+	assert.Equal(t, int32(3), atomic.LoadInt32(&peak), "should execute up to lookahead steps at the same time")
+	assert.True(t, atomic.LoadInt32(&calls) <= 8, "should not execute more than lookahead steps past the last step")
+
+	np := wp.Fork(1)
+	np.Close()
+	_, done, err := NewAsyncGenerator(func(interface{}, int) (interface{}, bool, error) {
+		return nil, false, nil
+	}, np, 2)(nil).Next()
+	assert.Equal(t, true, done, "should be done when the WorkerPool has been closed")
+	assert.Equal(t, ClosedWorkerPoolError{}, err, "should return a ClosedWorkerPoolError when the WorkerPool has been closed")
+}
@@ -0,0 +1,130 @@
+package futures
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// BulkheadFullError implements the error interface and is returned for a FutureFunc rejected by a Bulkhead partition that has no capacity left
+type BulkheadFullError struct {
+	Partition string
+}
+
+// Error returns an error message for BulkheadFullError
+func (e BulkheadFullError) Error() string {
+	return fmt.Sprintf("bulkhead partition %q is full", e.Partition)
+}
+
+// UnknownPartitionError implements the error interface and is returned for a FutureFunc sent to a Bulkhead partition that was not configured
+type UnknownPartitionError struct {
+	Partition string
+}
+
+// Error returns an error message for UnknownPartitionError
+func (e UnknownPartitionError) Error() string {
+	return fmt.Sprintf("bulkhead partition %q does not exist", e.Partition)
+}
+
+// BulkheadOption configures the partitions of a Bulkhead created with NewBulkhead
+type BulkheadOption func(*Bulkhead)
+
+// WithPartition adds a named partition to a Bulkhead that executes up to maxConcurrent FutureFuncs at a time and queues up to maxQueued more
+func WithPartition(name string, maxConcurrent, maxQueued int) BulkheadOption {
+	return func(b *Bulkhead) {
+		b.partitions[name] = &bulkheadPartition{
+			pool:          b.wp.Fork(maxConcurrent),
+			maxConcurrent: maxConcurrent,
+			maxQueued:     maxQueued,
+		}
+	}
+}
+
+// Bulkhead isolates named partitions of a shared WorkerPool from each other so that one partition cannot exhaust the worker go routines of the others. Each partition is a NestedWorkerPool forked from the WorkerPool.
+type Bulkhead struct {
+	wp         WorkerPoolInterface
+	lock       sync.Mutex
+	partitions map[string]*bulkheadPartition
+}
+
+type bulkheadPartition struct {
+	pool          WorkerPoolInterface
+	maxConcurrent int
+	maxQueued     int
+	active        int
+	queue         []func()
+}
+
+// NewBulkhead returns a Bulkhead with the partitions provided as BulkheadOptions which execute FutureFuncs on the provided WorkerPool
+func NewBulkhead(wp WorkerPoolInterface, opts ...BulkheadOption) *Bulkhead {
+	b := &Bulkhead{
+		wp:         wp,
+		partitions: map[string]*bulkheadPartition{},
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
+}
+
+// Execute returns a Future that resolves with the Value of fn once it has been executed in the named partition. When the partition is already executing and queueing as many FutureFuncs as it allows the Future resolves with a BulkheadFullError straight away.
+func (b *Bulkhead) Execute(partition string, fn FutureFunc, opts ...TaskOption) Future {
+	b.lock.Lock()
+	p, ok := b.partitions[partition]
+	if !ok {
+		b.lock.Unlock()
+		return rejected(UnknownPartitionError{partition})
+	}
+	if p.active >= p.maxConcurrent && len(p.queue) >= p.maxQueued {
+		b.lock.Unlock()
+		return rejected(BulkheadFullError{partition})
+	}
+	out := make(chan Value, 1)
+	start := func() {
+		if !p.pool.Do(out, fn, opts...) {
+			out <- Value{Error: ClosedWorkerPoolError{}}
+		}
+	}
+	if p.active < p.maxConcurrent {
+		p.active++
+		go start()
+	} else {
+		p.queue = append(p.queue, start)
+	}
+	b.lock.Unlock()
+	return resolve(func() Value {
+		v := <-out
+		b.release(p)
+		return v
+	})
+}
+
+// release starts the next queued FutureFunc of the partition once a FutureFunc has completed
+func (b *Bulkhead) release(p *bulkheadPartition) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if len(p.queue) > 0 {
+		next := p.queue[0]
+		p.queue = p.queue[1:]
+		go next()
+		return
+	}
+	p.active--
+}
+
+// Close closes the NestedWorkerPool of each partition without closing the shared WorkerPool
+func (b *Bulkhead) Close() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	for _, p := range b.partitions {
+		p.pool.Close()
+	}
+}
+
+func rejected(err error) Future {
+	return resolveWithContext(func() (Value, context.Context) {
+		return traced(nil, "futures.Bulkhead", func(context.Context) (interface{}, error) {
+			return nil, err
+		})
+	})
+}
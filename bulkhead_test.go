@@ -0,0 +1,40 @@
+package futures
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBulkhead(t *testing.T) {
+	wp := NewFuturesWorkerPool(4)
+	defer wp.Close()
+	bulkhead := NewBulkhead(wp, WithPartition("reports", 1, 1), WithPartition("api", 2, 0))
+	defer bulkhead.Close()
+
+	release := make(chan bool)
+	started := make(chan bool, 4)
+	block := func() (interface{}, error) {
+		started <- true
+		<-release
+		return "done", nil
+	}
+
+	running := bulkhead.Execute("reports", block)
+	<-started
+	queued := bulkhead.Execute("reports", block)
+	value := <-bulkhead.Execute("reports", block)
+	assert.Equal(t, BulkheadFullError{"reports"}, value.Error, "should reject FutureFuncs once the partition is full")
+
+	value = <-bulkhead.Execute("api", func() (interface{}, error) {
+		return "foobar", nil
+	})
+	assert.Equal(t, "foobar", value.Data, "should not be affected by other partitions being full")
+
+	close(release)
+	assert.Equal(t, "done", (<-running).Data, "should execute FutureFuncs within the concurrency of the partition")
+	assert.Equal(t, "done", (<-queued).Data, "should execute queued FutureFuncs once capacity frees up")
+
+	value = <-bulkhead.Execute("missing", block)
+	assert.Equal(t, UnknownPartitionError{"missing"}, value.Error, "should reject FutureFuncs sent to partitions that do not exist")
+}
@@ -0,0 +1,285 @@
+package futures
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Clock provides the current time to a CircuitBreaker so that the passage of time can be controlled in tests
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// CircuitState is the state of a CircuitBreaker
+type CircuitState int
+
+const (
+	// CircuitClosed allows every execution and counts failures
+	CircuitClosed CircuitState = iota
+	// CircuitOpen fails every execution with a CircuitOpenError until the open timeout has passed
+	CircuitOpen
+	// CircuitHalfOpen allows a limited number of trial executions that close the circuit when they succeed and open it again when any of them fails
+	CircuitHalfOpen
+)
+
+// String returns the name of the CircuitState
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+	return "closed"
+}
+
+// CircuitOpenError implements the error interface and is returned without executing the FutureFunc while a CircuitBreaker is open
+type CircuitOpenError struct{}
+
+// Error returns an error message for CircuitOpenError
+func (CircuitOpenError) Error() string {
+	return "circuit breaker is open"
+}
+
+// CircuitBreakerOption configures optional behavior of a CircuitBreaker created with NewCircuitBreaker
+type CircuitBreakerOption func(*CircuitBreaker)
+
+// WithFailureThreshold opens the circuit once the provided number of failures occurred within the rolling window. Defaults to 5.
+func WithFailureThreshold(failures int) CircuitBreakerOption {
+	return func(cb *CircuitBreaker) {
+		cb.threshold = failures
+	}
+}
+
+// WithRollingWindow counts failures within the last window split into the provided number of buckets. Defaults to 10 seconds split into 10 buckets.
+func WithRollingWindow(window time.Duration, buckets int) CircuitBreakerOption {
+	return func(cb *CircuitBreaker) {
+		cb.window = window
+		cb.buckets = make([]circuitBucket, buckets)
+	}
+}
+
+// WithOpenTimeout sets how long the circuit stays open before allowing trial executions. Defaults to 30 seconds.
+func WithOpenTimeout(timeout time.Duration) CircuitBreakerOption {
+	return func(cb *CircuitBreaker) {
+		cb.openTimeout = timeout
+	}
+}
+
+// WithHalfOpenRequests sets the number of trial executions allowed while the circuit is half-open which all have to succeed for the circuit to close. Defaults to 1.
+func WithHalfOpenRequests(requests int) CircuitBreakerOption {
+	return func(cb *CircuitBreaker) {
+		cb.halfOpenRequests = requests
+	}
+}
+
+// WithStateChange calls fn every time the CircuitBreaker changes state
+func WithStateChange(fn func(from, to CircuitState)) CircuitBreakerOption {
+	return func(cb *CircuitBreaker) {
+		cb.onStateChange = fn
+	}
+}
+
+// WithClock sets the Clock used for the rolling window and open timeout of a CircuitBreaker
+func WithClock(clock Clock) CircuitBreakerOption {
+	return func(cb *CircuitBreaker) {
+		cb.clock = clock
+	}
+}
+
+// CircuitBreaker fails fast with a CircuitOpenError once the FutureFuncs it executes have failed too often within a rolling window
+type CircuitBreaker struct {
+	lock             sync.Mutex
+	clock            Clock
+	threshold        int
+	window           time.Duration
+	buckets          []circuitBucket
+	openTimeout      time.Duration
+	halfOpenRequests int
+	onStateChange    func(from, to CircuitState)
+	state            CircuitState
+	generation       uint64
+	openedAt         time.Time
+	trials           int
+	successes        int
+}
+
+// circuitBucket counts the failures within one slice of the rolling window
+type circuitBucket struct {
+	epoch    int64
+	failures int
+}
+
+type circuitStateChange struct {
+	from, to CircuitState
+}
+
+// NewCircuitBreaker returns a closed CircuitBreaker configured with the provided CircuitBreakerOptions
+func NewCircuitBreaker(opts ...CircuitBreakerOption) *CircuitBreaker {
+	cb := &CircuitBreaker{
+		clock:            systemClock{},
+		threshold:        5,
+		window:           10 * time.Second,
+		buckets:          make([]circuitBucket, 10),
+		openTimeout:      30 * time.Second,
+		halfOpenRequests: 1,
+	}
+	for _, opt := range opts {
+		opt(cb)
+	}
+	if len(cb.buckets) == 0 {
+		cb.buckets = make([]circuitBucket, 1)
+	}
+	return cb
+}
+
+// State returns the current CircuitState
+func (cb *CircuitBreaker) State() CircuitState {
+	cb.lock.Lock()
+	var changes []circuitStateChange
+	cb.refresh(cb.clock.Now(), &changes)
+	state := cb.state
+	cb.lock.Unlock()
+	cb.notify(changes)
+	return state
+}
+
+// Execute returns a Future that resolves with the Value of fn or with a CircuitOpenError without executing fn while the circuit is open
+func (cb *CircuitBreaker) Execute(fn FutureFunc) Future {
+	return resolveWithContext(func() (Value, context.Context) {
+		return traced(nil, "futures.CircuitBreaker", func(context.Context) (interface{}, error) {
+			return cb.Wrap(fn)()
+		})
+	})
+}
+
+// Wrap returns a FutureFunc that executes fn through the CircuitBreaker
+func (cb *CircuitBreaker) Wrap(fn FutureFunc) FutureFunc {
+	return func() (interface{}, error) {
+		generation, err := cb.before()
+		if err != nil {
+			return nil, err
+		}
+		result, err := fn()
+		cb.after(generation, err)
+		return result, err
+	}
+}
+
+// WrapThenable returns a ThenableFunc that executes fn through the CircuitBreaker so that it can be used with Then, Series, Pipe and Map
+func (cb *CircuitBreaker) WrapThenable(fn ThenableFunc) ThenableFunc {
+	return func(v interface{}) (interface{}, error) {
+		return cb.Wrap(func() (interface{}, error) {
+			return fn(v)
+		})()
+	}
+}
+
+func (cb *CircuitBreaker) before() (uint64, error) {
+	cb.lock.Lock()
+	var changes []circuitStateChange
+	defer func() {
+		cb.lock.Unlock()
+		cb.notify(changes)
+	}()
+	cb.refresh(cb.clock.Now(), &changes)
+	switch cb.state {
+	case CircuitOpen:
+		return cb.generation, CircuitOpenError{}
+	case CircuitHalfOpen:
+		if cb.trials >= cb.halfOpenRequests {
+			return cb.generation, CircuitOpenError{}
+		}
+		cb.trials++
+	}
+	return cb.generation, nil
+}
+
+func (cb *CircuitBreaker) after(generation uint64, err error) {
+	cb.lock.Lock()
+	var changes []circuitStateChange
+	defer func() {
+		cb.lock.Unlock()
+		cb.notify(changes)
+	}()
+	now := cb.clock.Now()
+	cb.refresh(now, &changes)
+	if generation != cb.generation {
+		return
+	}
+	switch cb.state {
+	case CircuitClosed:
+		if err != nil && cb.fail(now) >= cb.threshold {
+			cb.setState(CircuitOpen, now, &changes)
+		}
+	case CircuitHalfOpen:
+		if err != nil {
+			cb.setState(CircuitOpen, now, &changes)
+			return
+		}
+		cb.successes++
+		if cb.successes >= cb.halfOpenRequests {
+			cb.setState(CircuitClosed, now, &changes)
+		}
+	}
+}
+
+// fail records a failure in the rolling window and returns the number of failures within the window
+func (cb *CircuitBreaker) fail(now time.Time) int {
+	width := int64(cb.window) / int64(len(cb.buckets))
+	if width < 1 {
+		width = 1
+	}
+	epoch := now.UnixNano() / width
+	bucket := &cb.buckets[int(epoch%int64(len(cb.buckets)))]
+	if bucket.epoch != epoch {
+		*bucket = circuitBucket{epoch: epoch}
+	}
+	bucket.failures++
+	failures := 0
+	for _, b := range cb.buckets {
+		if b.epoch > epoch-int64(len(cb.buckets)) {
+			failures += b.failures
+		}
+	}
+	return failures
+}
+
+// refresh moves an open circuit to half-open once the open timeout has passed
+func (cb *CircuitBreaker) refresh(now time.Time, changes *[]circuitStateChange) {
+	if cb.state == CircuitOpen && !now.Before(cb.openedAt.Add(cb.openTimeout)) {
+		cb.setState(CircuitHalfOpen, now, changes)
+	}
+}
+
+func (cb *CircuitBreaker) setState(state CircuitState, now time.Time, changes *[]circuitStateChange) {
+	*changes = append(*changes, circuitStateChange{cb.state, state})
+	cb.state = state
+	cb.generation++
+	cb.trials = 0
+	cb.successes = 0
+	switch state {
+	case CircuitOpen:
+		cb.openedAt = now
+	case CircuitClosed:
+		for i := range cb.buckets {
+			cb.buckets[i] = circuitBucket{}
+		}
+	}
+}
+
+func (cb *CircuitBreaker) notify(changes []circuitStateChange) {
+	if cb.onStateChange == nil {
+		return
+	}
+	for _, change := range changes {
+		cb.onStateChange(change.from, change.to)
+	}
+}
@@ -0,0 +1,113 @@
+package futures
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeClock struct {
+	lock sync.Mutex
+	now  time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+	var changes []string
+	cb := NewCircuitBreaker(
+		WithFailureThreshold(3),
+		WithRollingWindow(10*time.Second, 10),
+		WithOpenTimeout(time.Minute),
+		WithClock(clock),
+		WithStateChange(func(from, to CircuitState) {
+			changes = append(changes, fmt.Sprintf("%s->%s", from, to))
+		}),
+	)
+	calls := 0
+	failing := cb.Wrap(func() (interface{}, error) {
+		calls++
+		return nil, fmt.Errorf("some error")
+	})
+	succeeding := cb.Wrap(func() (interface{}, error) {
+		calls++
+		return "foobar", nil
+	})
+
+	failing()
+	failing()
+	clock.Advance(15 * time.Second)
+	failing()
+	assert.Equal(t, CircuitClosed, cb.State(), "should only count failures within the rolling window")
+	failing()
+	failing()
+	assert.Equal(t, CircuitOpen, cb.State(), "should open once the failure threshold is reached")
+
+	_, err := succeeding()
+	assert.Equal(t, CircuitOpenError{}, err, "should fail fast while open")
+	assert.Equal(t, 5, calls, "should not execute the FutureFunc while open")
+	value := <-cb.Execute(func() (interface{}, error) {
+		return nil, nil
+	})
+	assert.Equal(t, CircuitOpenError{}, value.Error, "should resolve Futures with a CircuitOpenError while open")
+
+	clock.Advance(time.Minute)
+	assert.Equal(t, CircuitHalfOpen, cb.State(), "should become half-open once the open timeout has passed")
+	failing()
+	assert.Equal(t, CircuitOpen, cb.State(), "should open again when a trial execution fails")
+
+	clock.Advance(time.Minute)
+	result, err := succeeding()
+	assert.Equal(t, "foobar", result, "should execute a trial while half-open")
+	assert.NoError(t, err, "should not return an error for a successful trial")
+	assert.Equal(t, CircuitClosed, cb.State(), "should close once the trial executions succeed")
+
+	assert.Equal(t, []string{"closed->open", "open->half-open", "half-open->open", "open->half-open", "half-open->closed"}, changes, "should call the state change callback for every transition")
+
+	results := <-Map([]interface{}{1, 2, 3, 4}, cb.WrapThenable(func(v interface{}) (interface{}, error) {
+		return v, nil
+	}), 2)
+	assert.Len(t, results.Data, 4, "should be usable with Map")
+}
+
+func TestCircuitBreakerHalfOpen(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+	cb := NewCircuitBreaker(WithFailureThreshold(1), WithOpenTimeout(time.Second), WithHalfOpenRequests(2), WithClock(clock))
+	cb.Wrap(func() (interface{}, error) {
+		return nil, fmt.Errorf("some error")
+	})()
+	clock.Advance(time.Second)
+
+	var started sync.WaitGroup
+	started.Add(2)
+	release := make(chan bool)
+	trial := cb.Wrap(func() (interface{}, error) {
+		started.Done()
+		<-release
+		return nil, nil
+	})
+	first, second := NewFuture(trial), NewFuture(trial)
+	started.Wait()
+	_, err := cb.Wrap(func() (interface{}, error) {
+		return nil, nil
+	})()
+	assert.Equal(t, CircuitOpenError{}, err, "should reject executions beyond the allowed trials while half-open")
+	close(release)
+	<-first
+	<-second
+	assert.Equal(t, CircuitClosed, cb.State(), "should close once all trials succeed")
+}
@@ -0,0 +1,242 @@
+package futures
+
+import (
+	"context"
+)
+
+// PredicateFunc specifies the function signature expected for filtering the values yielded by a Generator
+type PredicateFunc func(interface{}) bool
+
+// pull returns a function that reads the next value of gen and returns false once gen is done. If gen was closed or its context was done before it finished the error is returned as a final value.
+func pull(gen Generator) func() (GeneratorValue, bool) {
+	control := gen.control()
+	finished := false
+	return func() (GeneratorValue, bool) {
+		if finished {
+			return GeneratorValue{}, false
+		}
+		value, done, err := gen.Next()
+		finished = done
+		if done && control != nil {
+			select {
+			case <-control.finished:
+				if control.err != nil {
+					return GeneratorValue{Error: control.err}, true
+				}
+			default:
+			}
+		}
+		return GeneratorValue{Value: value, Error: err}, true
+	}
+}
+
+// closing returns a function that closes each of the provided Generators
+func closing(gens ...Generator) func() {
+	return func() {
+		for _, gen := range gens {
+			gen.Close()
+		}
+	}
+}
+
+// derive returns a Generator that yields each value returned by next until next returns false and calls release once it is done or closed.
+// The next value is read ahead so that the last value can be yielded as done.
+func derive(next func() (GeneratorValue, bool), release func()) Generator {
+	var held GeneratorValue
+	ok, started := false, false
+	return generate(context.Background(), func(context.Context, interface{}) (interface{}, bool, error) {
+		if !started {
+			held, ok = next()
+			started = true
+		}
+		if !ok {
+			return nil, true, nil
+		}
+		current := held
+		held, ok = next()
+		return current.Value, !ok, current.Error
+	}, nil, release)
+}
+
+// MapGen returns a Generator that yields the result of calling fn with each value yielded by gen. Values with an error are yielded as is without calling fn.
+func MapGen(gen Generator, fn ThenableFunc) Generator {
+	next := pull(gen)
+	return derive(func() (GeneratorValue, bool) {
+		v, ok := next()
+		if !ok || v.Error != nil {
+			return v, ok
+		}
+		v.Value, v.Error = fn(v.Value)
+		return v, true
+	}, closing(gen))
+}
+
+// FilterGen returns a Generator that only yields the values yielded by gen for which fn returns true. Values with an error are always yielded.
+func FilterGen(gen Generator, fn PredicateFunc) Generator {
+	next := pull(gen)
+	return derive(func() (GeneratorValue, bool) {
+		for {
+			v, ok := next()
+			if !ok || v.Error != nil || fn(v.Value) {
+				return v, ok
+			}
+		}
+	}, closing(gen))
+}
+
+// Take returns a Generator that yields the first n values yielded by gen and then closes gen
+func Take(gen Generator, n int) Generator {
+	next := pull(gen)
+	taken := 0
+	return derive(func() (GeneratorValue, bool) {
+		if taken >= n {
+			return GeneratorValue{}, false
+		}
+		taken++
+		return next()
+	}, closing(gen))
+}
+
+// TakeWhile returns a Generator that yields the values yielded by gen until fn returns false and then closes gen. Values with an error are yielded without calling fn.
+func TakeWhile(gen Generator, fn PredicateFunc) Generator {
+	next := pull(gen)
+	taking := true
+	return derive(func() (GeneratorValue, bool) {
+		if !taking {
+			return GeneratorValue{}, false
+		}
+		v, ok := next()
+		if ok && v.Error == nil && !fn(v.Value) {
+			taking = false
+			return GeneratorValue{}, false
+		}
+		return v, ok
+	}, closing(gen))
+}
+
+// Skip returns a Generator that yields the values yielded by gen after the first n values. Values with an error are yielded and are not counted.
+func Skip(gen Generator, n int) Generator {
+	next := pull(gen)
+	skipped := 0
+	return derive(func() (GeneratorValue, bool) {
+		for {
+			v, ok := next()
+			if !ok || v.Error != nil || skipped >= n {
+				return v, ok
+			}
+			skipped++
+		}
+	}, closing(gen))
+}
+
+// Zip returns a Generator that yields a slice with the next value of each of the provided Generators until any of them is done and then closes all of them.
+// When any of the values has an error the error is yielded instead of the slice.
+func Zip(gens ...Generator) Generator {
+	nexts := make([]func() (GeneratorValue, bool), len(gens))
+	for i, gen := range gens {
+		nexts[i] = pull(gen)
+	}
+	return derive(func() (GeneratorValue, bool) {
+		if len(nexts) == 0 {
+			return GeneratorValue{}, false
+		}
+		values := make([]interface{}, len(nexts))
+		var err error
+		for i, next := range nexts {
+			v, ok := next()
+			if !ok {
+				return GeneratorValue{}, false
+			}
+			if v.Error != nil && err == nil {
+				err = v.Error
+			}
+			values[i] = v.Value
+		}
+		if err != nil {
+			return GeneratorValue{Error: err}, true
+		}
+		return GeneratorValue{Value: values}, true
+	}, closing(gens...))
+}
+
+// Chain returns a Generator that yields all the values of each of the provided Generators in order
+func Chain(gens ...Generator) Generator {
+	nexts := make([]func() (GeneratorValue, bool), len(gens))
+	for i, gen := range gens {
+		nexts[i] = pull(gen)
+	}
+	return derive(func() (GeneratorValue, bool) {
+		for len(nexts) > 0 {
+			if v, ok := nexts[0](); ok {
+				return v, true
+			}
+			nexts = nexts[1:]
+		}
+		return GeneratorValue{}, false
+	}, closing(gens...))
+}
+
+// Window returns a Generator that yields a sliding window slice of size consecutive values yielded by gen. When gen yields fewer than size values a single shorter window is yielded.
+// Values with an error are yielded as is and are not added to a window.
+func Window(gen Generator, size int) Generator {
+	if size < 1 {
+		size = 1
+	}
+	next := pull(gen)
+	var window []interface{}
+	return derive(func() (GeneratorValue, bool) {
+		for {
+			v, ok := next()
+			if !ok {
+				if len(window) > 0 && len(window) < size {
+					partial := window
+					window = nil
+					return GeneratorValue{Value: partial}, true
+				}
+				return GeneratorValue{}, false
+			}
+			if v.Error != nil {
+				return v, true
+			}
+			if len(window) == size {
+				window = window[1:]
+			}
+			window = append(append([]interface{}{}, window...), v.Value)
+			if len(window) == size {
+				return GeneratorValue{Value: window}, true
+			}
+		}
+	}, closing(gen))
+}
+
+// Batch returns a Generator that yields slices of size consecutive values yielded by gen with the last slice containing the remaining values.
+// Values with an error are yielded as is and are not added to a batch.
+func Batch(gen Generator, size int) Generator {
+	if size < 1 {
+		size = 1
+	}
+	next := pull(gen)
+	var batch []interface{}
+	return derive(func() (GeneratorValue, bool) {
+		for {
+			v, ok := next()
+			if !ok {
+				if len(batch) > 0 {
+					rest := batch
+					batch = nil
+					return GeneratorValue{Value: rest}, true
+				}
+				return GeneratorValue{}, false
+			}
+			if v.Error != nil {
+				return v, true
+			}
+			batch = append(batch, v.Value)
+			if len(batch) >= size {
+				full := batch
+				batch = nil
+				return GeneratorValue{Value: full}, true
+			}
+		}
+	}, closing(gen))
+}
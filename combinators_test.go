@@ -0,0 +1,75 @@
+package futures
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func count(from, to int) Generator {
+	return NewGenerator(func(n interface{}) (interface{}, bool, error) {
+		return n.(int) + 1, n.(int)+1 >= to, nil
+	})(from - 1)
+}
+
+func naturals() Generator {
+	return NewGenerator(func(n interface{}) (interface{}, bool, error) {
+		return n.(int) + 1, false, nil
+	})(0)
+}
+
+func collect(gen Generator) []interface{} {
+	var results []interface{}
+	for v := range gen {
+		results = append(results, v.Value)
+	}
+	return results
+}
+
+func TestGeneratorCombinators(t *testing.T) {
+	double := func(v interface{}) (interface{}, error) {
+		return v.(int) * 2, nil
+	}
+	even := func(v interface{}) bool {
+		return v.(int)%2 == 0
+	}
+
+	assert.Equal(t, []interface{}{2, 4, 6}, collect(MapGen(count(1, 3), double)), "should map each value")
+	assert.Equal(t, []interface{}{2, 4}, collect(FilterGen(count(1, 5), even)), "should filter values and finish with the last accepted value")
+	assert.Equal(t, []interface{}{1, 2, 3}, collect(Take(naturals(), 3)), "should take the first values of an infinite generator")
+	assert.Equal(t, []interface{}{1, 2, 3}, collect(TakeWhile(naturals(), func(v interface{}) bool {
+		return v.(int) < 4
+	})), "should take values while the predicate is true")
+	assert.Equal(t, []interface{}{4, 5}, collect(Skip(count(1, 5), 3)), "should skip the first values")
+	assert.Equal(t, []interface{}{[]interface{}{1, "a"}, []interface{}{2, "b"}}, collect(Zip(naturals(), MapGen(count(1, 2), func(v interface{}) (interface{}, error) {
+		return string(rune('a' + v.(int) - 1)), nil
+	}))), "should zip generators until any of them is done")
+	assert.Equal(t, []interface{}{1, 2, 5, 6}, collect(Chain(count(1, 2), count(5, 6))), "should chain generators in order")
+	assert.Equal(t, []interface{}{[]interface{}{1, 2, 3}, []interface{}{2, 3, 4}}, collect(Window(count(1, 4), 3)), "should yield sliding windows")
+	assert.Equal(t, []interface{}{[]interface{}{1, 2}}, collect(Window(count(1, 2), 3)), "should yield a shorter window when there are too few values")
+	assert.Equal(t, []interface{}{[]interface{}{1, 2}, []interface{}{3, 4}, []interface{}{5}}, collect(Batch(count(1, 5), 2)), "should yield batches with the remaining values last")
+
+	composed := Take(MapGen(naturals(), double), 2)
+	value, done, _ := composed.Next()
+	assert.Equal(t, 2, value, "should compose combinators")
+	assert.Equal(t, false, done, "should not be done before the last value")
+	composed.Close()
+
+	failing := MapGen(count(1, 3), func(v interface{}) (interface{}, error) {
+		if v.(int) == 2 {
+			return nil, fmt.Errorf("some error")
+		}
+		return v, nil
+	})
+	var errs []error
+	for v := range FilterGen(MapGen(failing, double), even) {
+		errs = append(errs, v.Error)
+	}
+	assert.Equal(t, []error{nil, fmt.Errorf("some error"), nil}, errs, "should propagate errors through combinators")
+
+	assert.Eventually(t, func() bool {
+		return runningGenerators() == 0
+	}, time.Second, 10*time.Millisecond, "should close upstream generators once a combinator finishes early")
+}
@@ -0,0 +1,252 @@
+package futures
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DeadlockError implements the error interface and describes a WorkerPool whose workers are all blocked waiting on Futures of FutureFuncs that are queued behind them in the same WorkerPool
+type DeadlockError struct {
+	Workers int
+	Queued  int
+	Stacks  []string
+	Cycle   []DeadlockWait
+}
+
+// DeadlockWait describes a blocked worker go routine of a DeadlockError along with the Future it waits on and how many of the FutureFuncs resolving that Future have not started
+type DeadlockWait struct {
+	Goroutine int
+	Future    string
+	Pending   int
+	Stack     string
+}
+
+// Error returns an error message for DeadlockError
+func (e DeadlockError) Error() string {
+	return fmt.Sprintf("worker pool deadlocked: all %d workers are blocked on Futures of FutureFuncs queued behind them while %d tasks are queued", e.Workers, e.Queued)
+}
+
+// Report returns the error message followed by the Future each blocked worker waits on and its stack trace
+func (e DeadlockError) Report() string {
+	lines := []string{e.Error(), ""}
+	for _, wait := range e.Cycle {
+		lines = append(lines, fmt.Sprintf("goroutine %d waits on %s with %d FutureFuncs that have not started", wait.Goroutine, wait.Future, wait.Pending))
+	}
+	lines = append(lines, "none of them can start until one of the waiting workers is free", "")
+	return strings.Join(lines, "\n") + "\n" + strings.Join(e.Stacks, "\n\n")
+}
+
+// WithDeadlockDetection checks a WorkerPool every interval for a deadlock where every worker is blocked on a Future whose FutureFuncs are queued in the same WorkerPool and none have completed since
+// the last check. This happens when FutureFuncs call combinators such as AllWithWorkerPool with the WorkerPool executing them and wait on the result while all workers are doing the same. handler is
+// called with the DeadlockError once for each deadlock and a nil handler panics instead. Inspecting the worker go routines is expensive so this is meant for debugging.
+func WithDeadlockDetection(interval time.Duration, handler func(DeadlockError)) WorkerPoolOption {
+	return func(c *workerPoolConfig) {
+		c.detectInterval = interval
+		c.onDeadlock = handler
+		if handler == nil {
+			c.onDeadlock = func(err DeadlockError) {
+				panic(err)
+			}
+		}
+	}
+}
+
+// goroutines tracks the ids of the worker go routines of a WorkerPool with deadlock detection and the Futures they wait on
+type goroutines struct {
+	lock  sync.Mutex
+	ids   map[int]bool
+	waits map[int][]*dependency
+}
+
+// dependency is a Future that a worker go routine waits on along with the metrics of the NestedWorkerPool executing the FutureFuncs that resolve it
+type dependency struct {
+	future  string
+	tasks   int
+	metrics *poolMetrics
+}
+
+// pending returns the number of FutureFuncs resolving the dependency that have not started executing
+func (d *dependency) pending() int {
+	stats := d.metrics.snapshot()
+	return d.tasks - stats.BusyWorkers - int(stats.Completed)
+}
+
+// enter registers the calling worker go routine and returns a function that unregisters it
+func (g *goroutines) enter() func() {
+	if g == nil {
+		return func() {}
+	}
+	id := goroutineID()
+	g.lock.Lock()
+	g.ids[id] = true
+	g.lock.Unlock()
+	return func() {
+		g.lock.Lock()
+		delete(g.ids, id)
+		delete(g.waits, id)
+		g.lock.Unlock()
+	}
+}
+
+// depend records that the worker go routine calling it waits on a Future resolved by tasks FutureFuncs sent to wp. Returns a function that removes the dependency once the Future resolves.
+func depend(wp WorkerPoolInterface, future string, tasks int) func() {
+	np, ok := wp.(NestedWorkerPool)
+	if !ok || np.state.goroutines == nil {
+		return func() {}
+	}
+	g := np.state.goroutines
+	id := goroutineID()
+	d := &dependency{future: future, tasks: tasks, metrics: np.metrics}
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	if !g.ids[id] {
+		return func() {}
+	}
+	g.waits[id] = append(g.waits[id], d)
+	return func() {
+		g.lock.Lock()
+		defer g.lock.Unlock()
+		waits := g.waits[id]
+		for i, wait := range waits {
+			if wait == d {
+				g.waits[id] = append(waits[:i], waits[i+1:]...)
+				break
+			}
+		}
+		if len(g.waits[id]) == 0 {
+			delete(g.waits, id)
+		}
+	}
+}
+
+func goroutineID() int {
+	buf := make([]byte, 64)
+	buf = buf[:runtime.Stack(buf, false)]
+	buf = bytes.TrimPrefix(buf, []byte("goroutine "))
+	id, _ := strconv.Atoi(string(buf[:bytes.IndexByte(buf, ' ')]))
+	return id
+}
+
+// blockedStates are the go routine states of a worker that is waiting on another go routine
+var blockedStates = []string{"chan receive", "chan send", "select", "sync.Mutex.Lock", "sync.RWMutex", "sync.WaitGroup.Wait", "sync.Cond.Wait", "semacquire"}
+
+// forget removes the dependencies of the calling worker go routine once the FutureFunc that recorded them returns since it no longer waits on them
+func (g *goroutines) forget() {
+	if g == nil {
+		return
+	}
+	g.lock.Lock()
+	empty := len(g.waits) == 0
+	g.lock.Unlock()
+	if empty {
+		return
+	}
+	id := goroutineID()
+	g.lock.Lock()
+	delete(g.waits, id)
+	g.lock.Unlock()
+}
+
+// deadlocked returns what each worker go routine waits on or false if any of them is not blocked or does not wait on a Future whose FutureFuncs have not started
+func (g *goroutines) deadlocked() ([]DeadlockWait, bool) {
+	g.lock.Lock()
+	waits := make(map[int]DeadlockWait, len(g.ids))
+	for id := range g.ids {
+		for _, d := range g.waits[id] {
+			if pending := d.pending(); pending > 0 {
+				waits[id] = DeadlockWait{Goroutine: id, Future: d.future, Pending: pending}
+				break
+			}
+		}
+		if _, ok := waits[id]; !ok {
+			g.lock.Unlock()
+			return nil, false
+		}
+	}
+	g.lock.Unlock()
+	if len(waits) == 0 {
+		return nil, false
+	}
+
+	buf := make([]byte, 1<<16)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+	var cycle []DeadlockWait
+	for _, stack := range strings.Split(string(buf), "\n\n") {
+		var id int
+		var state string
+		header := strings.SplitN(stack, "\n", 2)[0]
+		if _, err := fmt.Sscanf(header, "goroutine %d", &id); err != nil {
+			continue
+		}
+		wait, ok := waits[id]
+		if !ok {
+			continue
+		}
+		if start, end := strings.Index(header, "["), strings.Index(header, "]"); start >= 0 && end > start {
+			state = header[start+1 : end]
+		}
+		waiting := false
+		for _, s := range blockedStates {
+			if strings.HasPrefix(state, s) {
+				waiting = true
+				break
+			}
+		}
+		if !waiting {
+			return nil, false
+		}
+		wait.Stack = stack
+		cycle = append(cycle, wait)
+	}
+	sort.Slice(cycle, func(i, j int) bool {
+		return cycle[i].Goroutine < cycle[j].Goroutine
+	})
+	return cycle, len(cycle) == len(waits)
+}
+
+// detectDeadlocks checks the WorkerPool every interval until it is closed and reports each deadlock once
+func (s *workerPoolState) detectDeadlocks(kill chan bool) {
+	ticker := time.NewTicker(s.detectInterval)
+	defer ticker.Stop()
+	completed := s.metrics.snapshot().Completed
+	reported := false
+	for {
+		select {
+		case <-kill:
+			return
+		case <-ticker.C:
+		}
+		stats := s.metrics.snapshot()
+		progressed := stats.Completed != completed
+		completed = stats.Completed
+		if progressed {
+			reported = false
+			continue
+		}
+		workers := s.size()
+		if reported || stats.BusyWorkers < workers {
+			continue
+		}
+		if cycle, ok := s.goroutines.deadlocked(); ok && len(cycle) == workers {
+			reported = true
+			stacks := make([]string, len(cycle))
+			for i, wait := range cycle {
+				stacks[i] = wait.Stack
+			}
+			s.onDeadlock(DeadlockError{Workers: workers, Queued: stats.QueueDepth, Stacks: stacks, Cycle: cycle})
+		}
+	}
+}
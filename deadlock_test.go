@@ -0,0 +1,109 @@
+package futures
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeadlockDetection(t *testing.T) {
+	reports := make(chan DeadlockError, 1)
+	wp := NewFuturesWorkerPool(2, WithDeadlockDetection(10*time.Millisecond, func(err DeadlockError) {
+		reports <- err
+	}))
+
+	release := make(chan struct{})
+	out := make(chan Value, 2)
+	for i := 0; i < 2; i++ {
+		wp.Do(out, func() (interface{}, error) {
+			f := AllWithWorkerPool([]interface{}{func() (interface{}, error) {
+				return "foobar", nil
+			}}, 1, wp)
+			select {
+			case v := <-f:
+				return v.Data, v.Error
+			case <-release:
+				return nil, nil
+			}
+		})
+	}
+
+	select {
+	case err := <-reports:
+		assert.Equal(t, 2, err.Workers, "should report the number of blocked workers")
+		assert.Equal(t, 2, len(err.Stacks), "should report the stack of each blocked worker")
+		assert.True(t, err.Queued > 0, "should report the number of queued FutureFuncs")
+		assert.True(t, strings.Contains(err.Report(), "TestDeadlockDetection"), "should include where the workers are blocked in the report")
+		assert.Len(t, err.Cycle, 2, "should report what each blocked worker waits on")
+		for _, wait := range err.Cycle {
+			assert.Equal(t, "futures.All", wait.Future, "should report the Future the worker waits on")
+			assert.Equal(t, 1, wait.Pending, "should report the FutureFuncs resolving the Future that have not started")
+		}
+		assert.True(t, strings.Contains(err.Report(), "waits on futures.All with 1 FutureFuncs that have not started"), "should include the cycle in the report")
+	case <-time.After(time.Second):
+		t.Error("should detect that every worker is waiting on FutureFuncs queued in the same WorkerPool")
+	}
+	close(release)
+	<-out
+	<-out
+	assert.Eventually(t, func() bool {
+		return wp.Stats().Completed == 4
+	}, time.Second, 10*time.Millisecond, "should execute the queued FutureFuncs once the workers are released")
+	wp.Close()
+
+	wp = NewFuturesWorkerPool(1, WithDeadlockDetection(5*time.Millisecond, func(err DeadlockError) {
+		reports <- err
+	}))
+	for i := 0; i < 3; i++ {
+		wp.Do(out, func() (interface{}, error) {
+			time.Sleep(20 * time.Millisecond)
+			return nil, nil
+		})
+	}
+	for i := 0; i < 3; i++ {
+		<-out
+	}
+	select {
+	case <-reports:
+		t.Error("should not report workers that are busy executing FutureFuncs")
+	default:
+	}
+	wp.Close()
+
+	wp = NewFuturesWorkerPool(1, WithDeadlockDetection(5*time.Millisecond, nil))
+	for i := 0; i < 3; i++ {
+		wp.Do(out, func() (interface{}, error) {
+			<-time.After(30 * time.Millisecond)
+			return nil, nil
+		})
+	}
+	for i := 0; i < 3; i++ {
+		<-out
+	}
+	wp.Close()
+
+	wp = NewFuturesWorkerPool(1, WithDeadlockDetection(5*time.Millisecond, func(err DeadlockError) {
+		reports <- err
+	}))
+	inner := make(chan Future, 1)
+	wp.Do(out, func() (interface{}, error) {
+		wp.Do(out, func() (interface{}, error) {
+			<-time.After(30 * time.Millisecond)
+			return nil, nil
+		})
+		inner <- AllWithWorkerPool([]interface{}{1}, 1, wp)
+		return nil, nil
+	})
+	for i := 0; i < 2; i++ {
+		<-out
+	}
+	assert.Equal(t, []interface{}{1}, (<-<-inner).Data, "should resolve the combinator once a worker is free")
+	select {
+	case <-reports:
+		t.Error("should not report workers that do not wait on the Futures of queued FutureFuncs")
+	default:
+	}
+	wp.Close()
+}
@@ -0,0 +1,91 @@
+package futures
+
+import (
+	"context"
+	"time"
+)
+
+// FallbackPolicy configures the timeout of each attempt of a fallback chain and which errors move on to the next FutureFunc.
+// Timeouts[i] limits the attempt of the i-th FutureFunc and Timeout applies to every attempt without an entry in Timeouts or with a zero entry. A zero timeout does not limit an attempt and a nil ShouldFallback falls back on every error.
+type FallbackPolicy struct {
+	Timeout        time.Duration
+	Timeouts       []time.Duration
+	ShouldFallback func(error) bool
+}
+
+// Fallback returns a Future that executes each FutureFunc in order until one of them succeeds and resolves with the error of the last FutureFunc if they all fail
+func Fallback(primary, secondary FutureFunc, rest ...FutureFunc) Future {
+	return FallbackPolicy{}.Fallback(primary, secondary, rest...)
+}
+
+// FallbackWithContext returns a Future that executes each ContextFutureFunc in order until one of them succeeds. Once ctx is done no further ContextFutureFuncs are executed and the Future resolves with the error of the context.
+func FallbackWithContext(ctx context.Context, primary, secondary ContextFutureFunc, rest ...ContextFutureFunc) Future {
+	return FallbackPolicy{}.FallbackWithContext(ctx, primary, secondary, rest...)
+}
+
+// Fallback calls Fallback but resolves a FutureFunc that does not complete within its timeout with a TimeoutError and only moves on to the next FutureFunc for errors accepted by ShouldFallback.
+// A FutureFunc that timed out cannot be cancelled so it keeps executing in the background and its result is discarded. Use FallbackWithContext to cancel attempts that time out.
+func (p FallbackPolicy) Fallback(primary, secondary FutureFunc, rest ...FutureFunc) Future {
+	fns := []ContextFutureFunc{withoutContext(primary), withoutContext(secondary)}
+	for _, fn := range rest {
+		fns = append(fns, withoutContext(fn))
+	}
+	return p.fallback(nil, fns)
+}
+
+// FallbackWithContext calls FallbackWithContext but cancels the context passed to a ContextFutureFunc once its timeout has passed and resolves the attempt with a TimeoutError
+func (p FallbackPolicy) FallbackWithContext(ctx context.Context, primary, secondary ContextFutureFunc, rest ...ContextFutureFunc) Future {
+	return p.fallback(ctx, append([]ContextFutureFunc{primary, secondary}, rest...))
+}
+
+func (p FallbackPolicy) fallback(ctx context.Context, fns []ContextFutureFunc) Future {
+	return resolveWithContext(func() (Value, context.Context) {
+		return traced(ctx, "futures.Fallback", func(ctx context.Context) (interface{}, error) {
+			var err error
+			for i, fn := range fns {
+				var result interface{}
+				if result, err = attempt(ctx, fn, p.timeout(i)); err == nil {
+					return result, nil
+				}
+				if ctx.Err() != nil {
+					return nil, ctx.Err()
+				}
+				if p.ShouldFallback != nil && !p.ShouldFallback(err) {
+					return nil, err
+				}
+			}
+			return nil, err
+		})
+	})
+}
+
+// timeout returns the timeout of the attempt of the i-th FutureFunc
+func (p FallbackPolicy) timeout(i int) time.Duration {
+	if i < len(p.Timeouts) && p.Timeouts[i] > 0 {
+		return p.Timeouts[i]
+	}
+	return p.Timeout
+}
+
+// attempt executes fn with a context that is cancelled once the timeout has passed. The attempt resolves with a TimeoutError without waiting for fn to return.
+func attempt(ctx context.Context, fn ContextFutureFunc, timeout time.Duration) (interface{}, error) {
+	if timeout <= 0 {
+		return fn(ctx)
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	result := make(chan Value, 1)
+	go func() {
+		data, err := fn(ctx)
+		result <- Value{Data: data, Error: err}
+	}()
+	select {
+	case v := <-result:
+		return v.Data, v.Error
+	case <-ctx.Done():
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, TimeoutError{timeout}
+		}
+		return nil, ctx.Err()
+	}
+}
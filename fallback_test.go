@@ -0,0 +1,81 @@
+package futures
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFallback(t *testing.T) {
+	var lock sync.Mutex
+	var tried []string
+	tier := func(name string, err error, delay time.Duration) FutureFunc {
+		return func() (interface{}, error) {
+			lock.Lock()
+			tried = append(tried, name)
+			lock.Unlock()
+			time.Sleep(delay)
+			return name, err
+		}
+	}
+	miss := fmt.Errorf("cache miss")
+
+	value := <-Fallback(tier("cache", miss, 0), tier("replica", nil, 0), tier("primary", nil, 0))
+	assert.Equal(t, "replica", value.Data, "should resolve with the first FutureFunc that succeeds")
+	assert.Equal(t, []string{"cache", "replica"}, tried, "should not execute the remaining FutureFuncs")
+
+	tried = nil
+	value = <-Fallback(tier("cache", miss, 0), tier("replica", fmt.Errorf("replica down"), 0))
+	assert.EqualError(t, value.Error, "replica down", "should resolve with the last error when every FutureFunc fails")
+
+	tried = nil
+	value = <-FallbackPolicy{Timeout: 10 * time.Millisecond}.Fallback(tier("cache", nil, 0), tier("replica", nil, 0))
+	assert.Equal(t, "cache", value.Data, "should resolve with a FutureFunc that completes within the timeout")
+	value = <-FallbackPolicy{Timeout: 10 * time.Millisecond}.Fallback(func() (interface{}, error) {
+		time.Sleep(100 * time.Millisecond)
+		return "cache", nil
+	}, tier("replica", nil, 0))
+	assert.Equal(t, "replica", value.Data, "should fall back once an attempt times out")
+
+	lock.Lock()
+	tried = nil
+	lock.Unlock()
+	value = <-FallbackPolicy{
+		Timeout:  time.Second,
+		Timeouts: []time.Duration{10 * time.Millisecond, 10 * time.Millisecond},
+	}.Fallback(tier("cache", nil, 100*time.Millisecond), tier("replica", nil, 100*time.Millisecond), tier("primary", nil, 100*time.Millisecond))
+	assert.Equal(t, "primary", value.Data, "should apply the timeout of each tier and fall back to Timeout for tiers without one")
+
+	cancelled := make(chan error, 1)
+	value = <-FallbackPolicy{Timeouts: []time.Duration{10 * time.Millisecond}}.FallbackWithContext(context.Background(), func(ctx context.Context) (interface{}, error) {
+		<-ctx.Done()
+		cancelled <- ctx.Err()
+		return nil, ctx.Err()
+	}, func(ctx context.Context) (interface{}, error) {
+		return "replica", nil
+	})
+	assert.Equal(t, "replica", value.Data, "should fall back once an attempt with a context times out")
+	assert.Equal(t, context.DeadlineExceeded, <-cancelled, "should cancel the context of an attempt that times out")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	value = <-FallbackWithContext(ctx, func(ctx context.Context) (interface{}, error) {
+		return nil, ctx.Err()
+	}, func(ctx context.Context) (interface{}, error) {
+		return "replica", nil
+	})
+	assert.Equal(t, context.Canceled, value.Error, "should not fall back once the context is done")
+
+	lock.Lock()
+	tried = nil
+	lock.Unlock()
+	value = <-FallbackPolicy{ShouldFallback: func(err error) bool {
+		return err == miss
+	}}.Fallback(tier("cache", miss, 0), tier("replica", fmt.Errorf("bad request"), 0), tier("primary", nil, 0))
+	assert.EqualError(t, value.Error, "bad request", "should stop at errors that should not fall back")
+	assert.Equal(t, []string{"cache", "replica"}, tried, "should only fall back for errors accepted by the predicate")
+}
@@ -1,8 +1,15 @@
 package futures
 
+import (
+	"context"
+)
+
 // FutureFunc specifies the function signature expected for a Future
 type FutureFunc func() (interface{}, error)
 
+// ContextFutureFunc specifies the function signature expected for a Future that receives a context
+type ContextFutureFunc func(context.Context) (interface{}, error)
+
 // ThenableFunc specifies the function signature expected for a Thenable
 type ThenableFunc func(interface{}) (interface{}, error)
 
@@ -36,54 +43,100 @@ func (f Future) resolveLast() (Value, error) {
 
 // Then uses function composition to execute a ThenableFunc in the order in which it was defined if there was no prior error returned with the result of the previous function
 func (f Future) Then(fn ThenableFunc) Future {
-	return NewFuture(func() (interface{}, error) {
+	link := chainSpan(f)
+	return resolveWithContext(func() (Value, context.Context) {
 		prev, err := f.resolveLast()
+		ctx := link.parent(f)
 		if err != nil {
-			return nil, err
+			return Value{Error: err}, nil
 		}
 		if prev.Error != nil {
-			return nil, prev.Error
+			return Value{Error: prev.Error}, ctx
 		}
-		return fn(prev.Data)
+		return traced(ctx, "futures.Then", func(context.Context) (interface{}, error) {
+			return fn(prev.Data)
+		})
 	})
 }
 
 // Catch uses function composition to execute a CatchableFunc in the order in which it was defined if there was a prior error returned with the error that was returned from the prior function
 func (f Future) Catch(fn CatchableFunc) Future {
-	return NewFuture(func() (interface{}, error) {
+	link := chainSpan(f)
+	return resolveWithContext(func() (Value, context.Context) {
 		prev, err := f.resolveLast()
+		ctx := link.parent(f)
 		if err != nil {
-			return nil, err
+			return Value{Error: err}, nil
 		}
 		if prev.Error == nil {
-			return prev.Data, nil
+			return prev, ctx
 		}
-		return fn(prev.Error)
+		return traced(ctx, "futures.Catch", func(context.Context) (interface{}, error) {
+			return fn(prev.Error)
+		})
 	})
 }
 
 // Finally uses function composition to execute a FutureFunc in the order in which it was defined regardless of the result of prior functions
 func (f Future) Finally(fn FutureFunc) Future {
-	return NewFuture(func() (interface{}, error) {
-		if _, err := f.resolveLast(); err != nil {
-			return nil, err
+	link := chainSpan(f)
+	return resolveWithContext(func() (Value, context.Context) {
+		_, err := f.resolveLast()
+		ctx := link.parent(f)
+		if err != nil {
+			return Value{Error: err}, nil
 		}
-		return fn()
+		return traced(ctx, "futures.Finally", func(context.Context) (interface{}, error) {
+			return fn()
+		})
 	})
 }
 
-// NewFuture returns a Future that propagates a Value containing the result of the execution of the defined FutureFunc argument
-func NewFuture(fn FutureFunc) Future {
+func resolve(fn func() Value) Future {
 	c := make(chan Value, 1)
 	go func() {
-		v := Value{}
-		v.Data, v.Error = fn()
+		c <- fn()
+		close(c)
+	}()
+	return c
+}
+
+// resolveWithContext is resolve for a Value produced within a Span. The Span's context is passed to the Then, Catch or Finally chained on the returned Future.
+// While a Tracer is set the go routine keeps the Span's context until the Value is received so that a step chained at any point before then still becomes a child of the Span.
+func resolveWithContext(fn func() (Value, context.Context)) Future {
+	if !tracing() {
+		return resolve(func() Value {
+			v, _ := fn()
+			return v
+		})
+	}
+	c := make(chan Value)
+	go func() {
+		v, ctx := fn()
+		received := publishSpan(c, ctx)
 		c <- v
+		received()
 		close(c)
 	}()
 	return c
 }
 
+// NewFuture returns a Future that propagates a Value containing the result of the execution of the defined FutureFunc argument
+func NewFuture(fn FutureFunc) Future {
+	return resolveWithContext(func() (Value, context.Context) {
+		return traced(nil, "futures.NewFuture", func(context.Context) (interface{}, error) {
+			return fn()
+		})
+	})
+}
+
+// NewFutureWithContext returns a Future that propagates a Value containing the result of the execution of the defined ContextFutureFunc argument. When tracing is enabled the Future and any Futures chained from it are traced as children of the span in ctx.
+func NewFutureWithContext(ctx context.Context, fn ContextFutureFunc) Future {
+	return resolveWithContext(func() (Value, context.Context) {
+		return traced(ctx, "futures.NewFuture", fn)
+	})
+}
+
 // Series executes ThenableFunc's in the order in which they appear in the argument slice with the argument for the first ThenableFunc being the first argument passed to Series
 func Series(argv interface{}, fns ...ThenableFunc) Future {
 	f := NewFuture(func() (interface{}, error) {
@@ -102,102 +155,237 @@ func Pipe(fns ...ThenableFunc) func(interface{}) Future {
 	}
 }
 
-func resolveSliceValuesFromWorkerPool(length int, wp WorkerPoolInterface) Future {
-	return NewFuture(func() (interface{}, error) {
+// resolveSliceValuesFromWorkerPool feeds fns to the WorkerPool from a single go routine so that a full queue holds back the remaining FutureFuncs instead of parking a go routine for each of them
+func resolveSliceValuesFromWorkerPool(ctx context.Context, span Span, name string, fns []FutureFunc, wp WorkerPoolInterface) Future {
+	resolved := depend(wp, name, len(fns))
+	feeding := ctx
+	if feeding == nil {
+		feeding = context.Background()
+	}
+	feeding, stop := context.WithCancel(feeding)
+	failed := make(chan error, 1)
+	go func() {
+		for _, fn := range fns {
+			if err := wp.SendWithContext(feeding, fn); err != nil {
+				failed <- err
+				wp.Close()
+				return
+			}
+		}
+	}()
+	return resolveWithContext(func() (Value, context.Context) {
+		defer span.End()
 		defer wp.Close()
+		defer stop()
+		defer resolved()
 		index := 0
-		result := make([]interface{}, length)
-		if length == 0 {
-			return result, nil
+		result := make([]interface{}, len(fns))
+		if len(fns) == 0 {
+			return Value{Data: result}, ctx
 		}
 		for {
-			if v, ok := wp.Receive(); ok && v.Error == nil {
-				result[index] = v.Data
-				index++
-				if index == length {
-					break
+			v, ok := wp.Receive()
+			if !ok {
+				select {
+				case v.Error = <-failed:
+				default:
+					v.Error = ClosedWorkerPoolError{}
 				}
-			} else {
-				return nil, v.Error
+			}
+			if v.Error != nil {
+				span.RecordError(v.Error)
+				return Value{Error: v.Error}, ctx
+			}
+			result[index] = v.Data
+			index++
+			if index == len(fns) {
+				break
 			}
 		}
-		return result, nil
+		return Value{Data: result}, ctx
 	})
 }
 
-// AllWithWorkerPool returns a Future which will resolve with all the values passed in the values argument. FutureFunc's and Futures passed in the argument are executed or resolved and all other values are returned as is. The provided WorkerPool is forked and closed at the end of execution.
-func AllWithWorkerPool(values []interface{}, concurrency int, wp WorkerPoolInterface) Future {
-	np := wp.Fork(concurrency)
-	for _, v := range values {
+// AllWithWorkerPool returns a Future which will resolve with all the values passed in the values argument. FutureFunc's and Futures passed in the argument are executed or resolved and all other values are returned as is. The provided WorkerPool is forked with any WorkerPoolOptions and closed at the end of execution.
+func AllWithWorkerPool(values []interface{}, concurrency int, wp WorkerPoolInterface, opts ...WorkerPoolOption) Future {
+	ctx, span := startSpan(nil, "futures.All")
+	np := wp.Fork(concurrency, append(opts, withContext(ctx))...)
+	fns := make([]FutureFunc, len(values))
+	for i, v := range values {
 		switch f := v.(type) {
 		case Future:
-			go np.Send(func() (interface{}, error) {
+			fns[i] = func() (interface{}, error) {
 				value := <-f
 				return value.Data, value.Error
-			})
-			break
+			}
 		case FutureFunc:
-			go np.Send(f)
-			break
+			fns[i] = f
 		case func() (interface{}, error):
-			go np.Send(f)
-			break
+			fns[i] = f
 		default:
-			go np.Send(func() (interface{}, error) {
+			fns[i] = func() (interface{}, error) {
 				return f, nil
-			})
+			}
 		}
 	}
-	return resolveSliceValuesFromWorkerPool(len(values), np)
-}
-
-// All calls AllWithWorkerPool but first creates a new WorkerPool with the specified concurrency
-func All(values []interface{}, concurrency int) Future {
-	wp := NewFuturesWorkerPool(concurrency)
-	return AllWithWorkerPool(values, concurrency, wp).
-		Then(func(value interface{}) (interface{}, error) {
-			defer wp.Close()
-			return value, nil
-		}).
-		Catch(func(err error) (interface{}, error) {
-			defer wp.Close()
-			return nil, err
-		})
+	return resolveSliceValuesFromWorkerPool(ctx, span, "futures.All", fns, np)
 }
 
-// MapWithWorkerPool calls the defined fn Thenabled argument with each of the values provided in the values arugment and returns a Future that will resolve with the resulting values. The provided WorkerPool is forked and closed at the end of execution.
-func MapWithWorkerPool(values []interface{}, fn ThenableFunc, concurrency int, wp WorkerPoolInterface) Future {
-	np := wp.Fork(concurrency)
-	for _, v := range values {
+// All calls AllWithWorkerPool but first creates a new WorkerPool with the specified concurrency and WorkerPoolOptions
+func All(values []interface{}, concurrency int, opts ...WorkerPoolOption) Future {
+	wp := NewFuturesWorkerPool(concurrency, opts...)
+	f := AllWithWorkerPool(values, concurrency, wp)
+	return resolve(func() Value {
+		defer wp.Close()
+		return <-f
+	})
+}
+
+// MapWithWorkerPool calls the defined fn Thenabled argument with each of the values provided in the values arugment and returns a Future that will resolve with the resulting values. The provided WorkerPool is forked with any WorkerPoolOptions and closed at the end of execution.
+func MapWithWorkerPool(values []interface{}, fn ThenableFunc, concurrency int, wp WorkerPoolInterface, opts ...WorkerPoolOption) Future {
+	ctx, span := startSpan(nil, "futures.Map")
+	np := wp.Fork(concurrency, append(opts, withContext(ctx))...)
+	fns := make([]FutureFunc, len(values))
+	for i, v := range values {
 		curr := v
-		go np.Send(func() (interface{}, error) {
+		fns[i] = func() (interface{}, error) {
 			return fn(curr)
-		})
+		}
 	}
-	return resolveSliceValuesFromWorkerPool(len(values), np)
-}
-
-// Map calls MapWithWorkerPool but first creates a WorkerPool with the specified concurrency
-func Map(values []interface{}, fn ThenableFunc, concurrency int) Future {
-	wp := NewFuturesWorkerPool(concurrency)
-	return MapWithWorkerPool(values, fn, concurrency, wp).
-		Then(func(value interface{}) (interface{}, error) {
-			defer wp.Close()
-			return value, nil
-		}).
-		Catch(func(err error) (interface{}, error) {
-			defer wp.Close()
-			return nil, err
-		})
+	return resolveSliceValuesFromWorkerPool(ctx, span, "futures.Map", fns, np)
+}
+
+// Map calls MapWithWorkerPool but first creates a WorkerPool with the specified concurrency and WorkerPoolOptions
+func Map(values []interface{}, fn ThenableFunc, concurrency int, opts ...WorkerPoolOption) Future {
+	wp := NewFuturesWorkerPool(concurrency, opts...)
+	f := MapWithWorkerPool(values, fn, concurrency, wp)
+	return resolve(func() Value {
+		defer wp.Close()
+		return <-f
+	})
 }
 
 // Co returns a Future that iterates through the provided Generator and resolves with the last yielded value
 func Co(generator Generator) Future {
-	return NewFuture(func() (interface{}, error) {
-		var last GeneratorValue
-		for v := range generator {
-			last = v
-		}
-		return last.Value, last.Error
+	return co(nil, generator)
+}
+
+// CoWithContext calls Co but closes the Generator and resolves with the error of the context once the context is done
+func CoWithContext(ctx context.Context, generator Generator) Future {
+	return co(ctx, generator)
+}
+
+func co(ctx context.Context, generator Generator) Future {
+	control := generator.control()
+	var cancelled <-chan struct{}
+	if ctx != nil {
+		cancelled = ctx.Done()
+	}
+	return resolveWithContext(func() (Value, context.Context) {
+		return traced(ctx, "futures.Co", func(context.Context) (interface{}, error) {
+			var last GeneratorValue
+			for {
+				select {
+				case v, ok := <-generator:
+					if !ok {
+						if control != nil && !last.done && control.err != nil {
+							return nil, control.err
+						}
+						return last.Value, last.Error
+					}
+					last = v
+				case <-cancelled:
+					generator.Close()
+					return nil, ctx.Err()
+				}
+			}
+		})
 	})
 }
+
+// CoAwait returns a Future that drives the provided Generator in the style of co.js and resolves with its final value.
+// When the GeneratorFunc yields a Future the Generator is resumed with the resolved data of the Future using Send or with its error using Throw. Yielding a slice of Futures resumes the Generator with the resolved data of all of them in order and any other value is sent back as is.
+// The Generator must be created with NewGenerator or NewGeneratorWithContext and should not have been read from before it is passed to CoAwait.
+func CoAwait(generator Generator) Future {
+	return coAwait(nil, generator)
+}
+
+// CoAwaitWithContext calls CoAwait but closes the Generator and resolves with the error of the context once the context is done
+func CoAwaitWithContext(ctx context.Context, generator Generator) Future {
+	return coAwait(ctx, generator)
+}
+
+func coAwait(ctx context.Context, generator Generator) Future {
+	control := generator.control()
+	var cancelled <-chan struct{}
+	if ctx != nil {
+		cancelled = ctx.Done()
+	}
+	return resolveWithContext(func() (Value, context.Context) {
+		return traced(ctx, "futures.CoAwait", func(context.Context) (interface{}, error) {
+			value, done, err := generator.Send(nil)
+			for {
+				if err != nil {
+					generator.Close()
+					return nil, err
+				}
+				if done && control != nil {
+					<-control.finished
+					if control.err != nil {
+						return nil, control.err
+					}
+				}
+				data, ok, awaitErr := await(cancelled, value)
+				if !ok {
+					generator.Close()
+					return nil, ctx.Err()
+				}
+				if done {
+					return data, awaitErr
+				}
+				if awaitErr != nil {
+					value, done, err = generator.Throw(awaitErr)
+				} else {
+					value, done, err = generator.Send(data)
+				}
+			}
+		})
+	})
+}
+
+// await resolves a Future or each Future in a slice yielded to CoAwait and returns false if cancelled is closed first
+func await(cancelled <-chan struct{}, v interface{}) (interface{}, bool, error) {
+	switch f := v.(type) {
+	case Future:
+		select {
+		case value, ok := <-f:
+			if !ok {
+				return nil, true, ResolvedFutureError{}
+			}
+			return value.Data, true, value.Error
+		case <-cancelled:
+			return nil, false, nil
+		}
+	case []Future:
+		result := make([]interface{}, len(f))
+		for i, future := range f {
+			data, ok, err := await(cancelled, future)
+			if !ok || err != nil {
+				return nil, ok, err
+			}
+			result[i] = data
+		}
+		return result, true, nil
+	case []interface{}:
+		result := make([]interface{}, len(f))
+		for i, value := range f {
+			data, ok, err := await(cancelled, value)
+			if !ok || err != nil {
+				return nil, ok, err
+			}
+			result[i] = data
+		}
+		return result, true, nil
+	}
+	return v, true, nil
+}
@@ -1,7 +1,9 @@
 package futures
 
 import (
+	"context"
 	"fmt"
+	"sync/atomic"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -171,6 +173,17 @@ func TestMap(t *testing.T) {
 		return nil, fmt.Errorf("some error")
 	}, 1)
 	assert.Error(t, result.Error, "should resolve with an error if any Future's or FutureFunc's return an error")
+
+	var calls int32
+	result = <-Map([]interface{}{1, 2, 3}, func(value interface{}) (interface{}, error) {
+		return value, nil
+	}, 2, Use(func(fn FutureFunc) FutureFunc {
+		return func() (interface{}, error) {
+			atomic.AddInt32(&calls, 1)
+			return fn()
+		}
+	}))
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls), "should apply WorkerPool Middleware to each mapped value")
 }
 
 func TestCo(t *testing.T) {
@@ -181,3 +194,55 @@ func TestCo(t *testing.T) {
 	result := <-Co(generator(0))
 	assert.Equal(t, 5, result.Data, "should resolve the final value yielded from generator")
 }
+
+func TestCoAwait(t *testing.T) {
+	double := func(n int) Future {
+		return NewFuture(func() (interface{}, error) {
+			return n * 2, nil
+		})
+	}
+	step := 0
+	generator := NewGenerator(func(input interface{}) (interface{}, bool, error) {
+		step++
+		switch step {
+		case 1:
+			return double(input.(int)), false, nil
+		case 2:
+			n := input.(int)
+			return []Future{double(n), double(n + 1)}, false, nil
+		case 3:
+			results := input.([]interface{})
+			return NewFuture(func() (interface{}, error) {
+				return nil, fmt.Errorf("failed with %v", results)
+			}), false, nil
+		default:
+			err := input.(error)
+			return double(len(err.Error())), true, nil
+		}
+	})
+	result := <-CoAwait(generator(1))
+	assert.NoError(t, result.Error, "should resolve without an error when the thrown error is handled")
+	assert.Equal(t, 2*len("failed with [4 6]"), result.Data, "should resume the generator with the resolved values of yielded Futures")
+
+	result = <-CoAwait(NewGenerator(func(input interface{}) (interface{}, bool, error) {
+		if err, ok := input.(error); ok {
+			return nil, true, err
+		}
+		return NewFuture(func() (interface{}, error) {
+			return nil, fmt.Errorf("some error")
+		}), false, nil
+	})(nil))
+	assert.EqualError(t, result.Error, "some error", "should resolve with the error of a yielded Future that is not handled")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	never := make(chan Value)
+	pending := NewGenerator(func(input interface{}) (interface{}, bool, error) {
+		return Future(never), false, nil
+	})(nil)
+	f := CoAwaitWithContext(ctx, pending)
+	cancel()
+	result = <-f
+	assert.Equal(t, context.Canceled, result.Error, "should resolve with the error of the context once it is cancelled")
+	_, done, _ := pending.Next()
+	assert.Equal(t, true, done, "should close the generator once the context is cancelled")
+}
@@ -0,0 +1,90 @@
+package futures
+
+import (
+	"context"
+	"sync"
+)
+
+// FutureSet is a completion queue for a dynamic set of Futures that returns each Future's Value in the order in which they resolve
+type FutureSet struct {
+	lock     sync.Mutex
+	pending  int
+	resolved []futureSetEntry
+	notify   chan struct{}
+}
+
+type futureSetEntry struct {
+	key   interface{}
+	value Value
+}
+
+// NewFutureSet returns an empty FutureSet
+func NewFutureSet() *FutureSet {
+	return &FutureSet{notify: make(chan struct{}, 1)}
+}
+
+// signal wakes up a caller waiting for the next resolved Future
+func (s *FutureSet) signal() {
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+}
+
+// Add adds a Future to the FutureSet under the provided key. Futures can be added at any time including while other callers are waiting on Next.
+func (s *FutureSet) Add(key interface{}, f Future) {
+	s.lock.Lock()
+	s.pending++
+	s.lock.Unlock()
+	go func() {
+		value, err := f.resolveLast()
+		if err != nil {
+			value = Value{Error: err}
+		}
+		s.lock.Lock()
+		s.pending--
+		s.resolved = append(s.resolved, futureSetEntry{key, value})
+		s.lock.Unlock()
+		s.signal()
+	}()
+}
+
+// Len returns the number of Futures in the FutureSet that have not yet been returned by Next
+func (s *FutureSet) Len() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.pending + len(s.resolved)
+}
+
+// Next blocks until a Future in the FutureSet resolves and returns its key and Value. Returns false if there are no Futures left in the FutureSet.
+func (s *FutureSet) Next() (interface{}, Value, bool) {
+	return s.NextWithContext(context.Background())
+}
+
+// NextWithContext calls Next but returns the error of the context as the Value once the context is done
+func (s *FutureSet) NextWithContext(ctx context.Context) (interface{}, Value, bool) {
+	for {
+		s.lock.Lock()
+		if len(s.resolved) > 0 {
+			entry := s.resolved[0]
+			s.resolved = s.resolved[1:]
+			more := len(s.resolved) > 0 || s.pending == 0
+			s.lock.Unlock()
+			if more {
+				s.signal()
+			}
+			return entry.key, entry.value, true
+		}
+		if s.pending == 0 {
+			s.lock.Unlock()
+			s.signal()
+			return nil, Value{}, false
+		}
+		s.lock.Unlock()
+		select {
+		case <-s.notify:
+		case <-ctx.Done():
+			return nil, Value{Error: ctx.Err()}, true
+		}
+	}
+}
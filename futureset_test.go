@@ -0,0 +1,56 @@
+package futures
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFutureSet(t *testing.T) {
+	after := func(d time.Duration, data interface{}, err error) Future {
+		return NewFuture(func() (interface{}, error) {
+			time.Sleep(d)
+			return data, err
+		})
+	}
+
+	set := NewFutureSet()
+	_, _, ok := set.Next()
+	assert.Equal(t, false, ok, "should return false when the set is empty")
+
+	set.Add("slow", after(30*time.Millisecond, 3, nil))
+	set.Add("fast", after(0, 1, nil))
+	set.Add("failed", after(10*time.Millisecond, nil, fmt.Errorf("some error")))
+	assert.Equal(t, 3, set.Len(), "should count the futures in the set")
+
+	key, value, ok := set.Next()
+	assert.Equal(t, true, ok, "should return a resolved future")
+	assert.Equal(t, "fast", key, "should return the key of the first future to resolve")
+	assert.Equal(t, 1, value.Data, "should return the value of the first future to resolve")
+
+	set.Add("added", after(20*time.Millisecond, 2, nil))
+	var keys []interface{}
+	for {
+		key, value, ok := set.Next()
+		if !ok {
+			break
+		}
+		if key == "failed" {
+			assert.EqualError(t, value.Error, "some error", "should return the error of a future")
+		}
+		keys = append(keys, key)
+	}
+	assert.Equal(t, []interface{}{"failed", "added", "slow"}, keys, "should drain futures in completion order including futures added later")
+	assert.Equal(t, 0, set.Len(), "should be empty once drained")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	never := make(chan Value)
+	defer close(never)
+	set.Add("never", never)
+	cancel()
+	_, value, _ = set.NextWithContext(ctx)
+	assert.Equal(t, context.Canceled, value.Error, "should return the error of the context once it is done")
+}
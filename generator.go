@@ -1,9 +1,26 @@
 package futures
 
+import (
+	"context"
+	"sync"
+)
+
 // GeneratorFunc specifies the function signature for each step of the generator execution.
 // Returns an interface result, boolean done status and error value with the result of the function being passed as the argument to the next invocation.
+// When a value is sent to the Generator with Send it is passed as the argument instead and when an error is thrown into the Generator with Throw the error is passed as the argument.
 type GeneratorFunc func(interface{}) (interface{}, bool, error)
 
+// ContextGeneratorFunc specifies the function signature for each step of a Generator created with NewGeneratorWithContext and receives the context of the Generator
+type ContextGeneratorFunc func(context.Context, interface{}) (interface{}, bool, error)
+
+// ClosedGeneratorError implements the error interface and returns a standard error for a Generator that was closed before it was done
+type ClosedGeneratorError struct{}
+
+// Error returns an error message for ClosedGeneratorError
+func (ClosedGeneratorError) Error() string {
+	return "generator has been closed"
+}
+
 // GeneratorValue contains the output of a GeneratorFunc execution
 type GeneratorValue struct {
 	done  bool
@@ -14,31 +31,200 @@ type GeneratorValue struct {
 // Generator is a read-only channel the receives the results of the stepwise execution of GeneratorFunc's
 type Generator <-chan GeneratorValue
 
+type generatorCommandKind int
+
+const (
+	generatorNext generatorCommandKind = iota
+	generatorSend
+	generatorThrow
+	generatorReturn
+)
+
+type generatorCommand struct {
+	kind  generatorCommandKind
+	value interface{}
+	reply chan GeneratorValue
+}
+
+// generatorControl allows the consumer of a Generator to resume its producer go routine
+type generatorControl struct {
+	commands  chan generatorCommand
+	finished  chan struct{}
+	closing   chan struct{}
+	closeOnce sync.Once
+	err       error
+}
+
+// generators maps each Generator created by NewGenerator to its generatorControl while its producer go routine is running
+var generators sync.Map
+
+func (gen Generator) command(kind generatorCommandKind, value interface{}) (GeneratorValue, bool) {
+	c, ok := generators.Load(gen)
+	if !ok {
+		return GeneratorValue{}, false
+	}
+	control := c.(*generatorControl)
+	reply := make(chan GeneratorValue, 1)
+	select {
+	case control.commands <- generatorCommand{kind, value, reply}:
+		return <-reply, true
+	case <-control.finished:
+		return GeneratorValue{}, false
+	}
+}
+
 // Next yields the result of the current step of the GeneratorFunc's execution
 func (gen Generator) Next() (interface{}, bool, error) {
-	if next, ok := <-gen; ok {
+	c, ok := generators.Load(gen)
+	if !ok {
+		if next, ok := <-gen; ok {
+			return next.Value, next.done, next.Error
+		}
+		return nil, true, nil
+	}
+	control := c.(*generatorControl)
+	reply := make(chan GeneratorValue, 1)
+	select {
+	case next, ok := <-gen:
+		if ok {
+			return next.Value, next.done, next.Error
+		}
+	case control.commands <- generatorCommand{generatorNext, nil, reply}:
+		next := <-reply
 		return next.Value, next.done, next.Error
 	}
 	return nil, true, nil
 }
 
+// Send resumes the Generator with v as the argument of its next step that has not yet been executed and yields the next result.
+// Since a Generator computes its first result as soon as it is created the value passed to the first call of Send is ignored.
+// Once a Generator has been resumed with Send, Throw or Return it no longer executes steps ahead of time so each step is only executed when it is requested with Next, Send, Throw or Return.
+func (gen Generator) Send(v interface{}) (interface{}, bool, error) {
+	if next, ok := gen.command(generatorSend, v); ok {
+		return next.Value, next.done, next.Error
+	}
+	return nil, true, nil
+}
+
+// Throw resumes the Generator with err as the argument of its next step that has not yet been executed and yields the next result so that the GeneratorFunc can handle the error
+func (gen Generator) Throw(err error) (interface{}, bool, error) {
+	if next, ok := gen.command(generatorThrow, err); ok {
+		return next.Value, next.done, next.Error
+	}
+	return nil, true, err
+}
+
+// Return finishes the Generator without executing any further steps and yields v as its final result
+func (gen Generator) Return(v interface{}) (interface{}, bool, error) {
+	gen.command(generatorReturn, v)
+	return v, true, nil
+}
+
+// Close stops the producer go routine of the Generator without executing any further steps and closes the Generator channel. Returns false if the Generator is already done or has been closed.
+func (gen Generator) Close() bool {
+	c, ok := generators.Load(gen)
+	if !ok {
+		return false
+	}
+	control := c.(*generatorControl)
+	closed := false
+	control.closeOnce.Do(func() {
+		close(control.closing)
+		closed = true
+	})
+	return closed
+}
+
+// control returns the generatorControl of a Generator created by NewGenerator whose producer go routine is still running
+func (gen Generator) control() *generatorControl {
+	if c, ok := generators.Load(gen); ok {
+		return c.(*generatorControl)
+	}
+	return nil
+}
+
 // NewGenerator returns a factory method for creating Generator's.
 // The argument of the factory function is passed as the initial input to the GeneratorFunc.
 func NewGenerator(fn GeneratorFunc) func(interface{}) Generator {
 	return func(argv interface{}) Generator {
-		out := make(chan GeneratorValue, 1)
-		go func() {
-			input := argv
-			for {
-				result, done, err := fn(input)
-				input = result
-				out <- GeneratorValue{done, result, err}
-				if done {
-					close(out)
+		return generate(context.Background(), func(_ context.Context, input interface{}) (interface{}, bool, error) {
+			return fn(input)
+		}, argv, nil)
+	}
+}
+
+// NewGeneratorWithContext returns a factory method for creating Generator's that stop executing steps and close once the context passed to the factory is done.
+// The argument of the factory function is passed as the initial input to the ContextGeneratorFunc.
+func NewGeneratorWithContext(fn ContextGeneratorFunc) func(context.Context, interface{}) Generator {
+	return func(ctx context.Context, argv interface{}) Generator {
+		return generate(ctx, fn, argv, nil)
+	}
+}
+
+// generate starts the producer go routine of a Generator which calls release once it exits if it is not nil
+func generate(ctx context.Context, fn ContextGeneratorFunc, argv interface{}, release func()) Generator {
+	out := make(chan GeneratorValue)
+	control := &generatorControl{
+		commands: make(chan generatorCommand),
+		finished: make(chan struct{}),
+		closing:  make(chan struct{}),
+	}
+	generators.Store(Generator(out), control)
+	go func() {
+		defer close(out)
+		defer close(control.finished)
+		defer generators.Delete(Generator(out))
+		if release != nil {
+			defer release()
+		}
+		input := argv
+		lazy := false
+		var pending *GeneratorValue
+		step := func() {
+			result, done, err := fn(ctx, input)
+			input = result
+			pending = &GeneratorValue{done, result, err}
+		}
+		for {
+			if pending == nil && !lazy {
+				step()
+			}
+			var offer chan GeneratorValue
+			var next GeneratorValue
+			if pending != nil {
+				offer = out
+				next = *pending
+			}
+			select {
+			case offer <- next:
+			case <-control.closing:
+				control.err = ClosedGeneratorError{}
+				return
+			case <-ctx.Done():
+				control.err = ctx.Err()
+				return
+			case cmd := <-control.commands:
+				switch cmd.kind {
+				case generatorSend, generatorThrow:
+					if pending == nil || cmd.kind == generatorThrow {
+						input = cmd.value
+					}
+					lazy = true
+				case generatorReturn:
+					cmd.reply <- GeneratorValue{true, cmd.value, nil}
 					return
 				}
+				if pending == nil {
+					step()
+				}
+				next = *pending
+				cmd.reply <- next
 			}
-		}()
-		return out
-	}
+			if next.done {
+				return
+			}
+			pending = nil
+		}
+	}()
+	return out
 }
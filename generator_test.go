@@ -1,7 +1,12 @@
 package futures
 
 import (
+	"context"
+	"fmt"
+	"runtime"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -30,3 +35,109 @@ func TestNewGenerator(t *testing.T) {
 	_, done, _ := generator.Next()
 	assert.Equal(t, true, done, "should return true for done value when next is called after generator has yielded its last value")
 }
+
+func TestGeneratorSend(t *testing.T) {
+	var total, calls int
+	generator := NewGenerator(func(input interface{}) (interface{}, bool, error) {
+		calls++
+		if err, ok := input.(error); ok {
+			return total, true, err
+		}
+		if n, ok := input.(int); ok {
+			total += n
+		}
+		return total, false, nil
+	})(1)
+
+	value, done, _ := generator.Send(100)
+	assert.Equal(t, 1, value, "should yield the first result when Send is called first")
+	assert.Equal(t, false, done, "should not be done after the first call of Send")
+	value, _, _ = generator.Next()
+	assert.Equal(t, 2, value, "should ignore the value passed to the first call of Send")
+
+	value, _, _ = generator.Send(5)
+	assert.Equal(t, 7, value, "should pass the sent value as the argument of the next step")
+	value, _, _ = generator.Send(10)
+	assert.Equal(t, 17, value, "should pass the sent value as the argument of the next step")
+	assert.Equal(t, 4, calls, "should not execute steps ahead of time once a value has been sent")
+
+	value, _, _ = generator.Next()
+	assert.Equal(t, 34, value, "should pass the previous result as the argument of the next step when Next is called")
+
+	value, done, err := generator.Throw(fmt.Errorf("some error"))
+	assert.Equal(t, 34, value, "should pass the thrown error as the argument of the next step")
+	assert.Equal(t, true, done, "should allow the GeneratorFunc to finish when an error is thrown")
+	assert.Error(t, err, "should allow the GeneratorFunc to return the thrown error")
+
+	_, done, _ = generator.Send(1)
+	assert.Equal(t, true, done, "should return true for done value when Send is called after generator has yielded its last value")
+	assert.Equal(t, 6, calls, "should not execute any steps once the generator is done")
+}
+
+func TestGeneratorReturn(t *testing.T) {
+	var calls int
+	generator := NewGenerator(func(input interface{}) (interface{}, bool, error) {
+		calls++
+		return input.(int) + 1, false, nil
+	})(0)
+
+	value, _, _ := generator.Next()
+	assert.Equal(t, 1, value, "should get next value")
+
+	value, done, err := generator.Return("foobar")
+	assert.Equal(t, "foobar", value, "should yield the returned value")
+	assert.Equal(t, true, done, "should be done once Return is called")
+	assert.NoError(t, err, "should not return an error when Return is called")
+
+	_, done, _ = generator.Next()
+	assert.Equal(t, true, done, "should return true for done value when Next is called after Return")
+	assert.Equal(t, 2, calls, "should not execute any more steps once Return is called")
+
+	_, done, err = generator.Throw(fmt.Errorf("some error"))
+	assert.Equal(t, true, done, "should return true for done value when Throw is called after generator is done")
+	assert.Error(t, err, "should return the thrown error when the generator is done")
+}
+
+// runningGenerators counts the producer go routines of Generators that have not yet exited
+func runningGenerators() int {
+	buf := make([]byte, 1<<20)
+	return strings.Count(string(buf[:runtime.Stack(buf, true)]), "futures.generate.func")
+}
+
+func TestGeneratorClose(t *testing.T) {
+	infinite := func(input interface{}) (interface{}, bool, error) {
+		return input.(int) + 1, false, nil
+	}
+
+	generator := NewGenerator(infinite)(0)
+	generator.Next()
+	assert.Equal(t, true, generator.Close(), "should close a generator that is not done")
+	assert.Equal(t, false, generator.Close(), "should not close a generator more than once")
+	for range generator {
+	}
+	_, done, _ := generator.Next()
+	assert.Equal(t, true, done, "should return true for done value when Next is called after Close")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	generator = NewGeneratorWithContext(func(ctx context.Context, input interface{}) (interface{}, bool, error) {
+		return infinite(input)
+	})(ctx, 0)
+	generator.Next()
+	cancel()
+	for range generator {
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	result := CoWithContext(ctx, NewGenerator(infinite)(0))
+	cancel()
+	value := <-result
+	assert.Equal(t, context.Canceled, value.Error, "should resolve Co with the error of the context once it is cancelled")
+
+	assert.Equal(t, 5, (<-Co(NewGenerator(func(input interface{}) (interface{}, bool, error) {
+		return input.(int) + 1, input.(int) >= 4, nil
+	})(0))).Data, "should resolve Co with the final value when the context is not cancelled")
+
+	assert.Eventually(t, func() bool {
+		return runningGenerators() == 0
+	}, time.Second, 10*time.Millisecond, "should not leak the go routines of closed or cancelled generators")
+}
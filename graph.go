@@ -0,0 +1,210 @@
+package futures
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// GraphFunc specifies the function signature expected for a node of a Graph. The results of the dependencies of the node are passed keyed by their names.
+type GraphFunc func(map[string]interface{}) (interface{}, error)
+
+// DuplicateNodeError implements the error interface and is returned when a Graph contains more than one node with the same name
+type DuplicateNodeError struct {
+	Node string
+}
+
+// Error returns an error message for DuplicateNodeError
+func (e DuplicateNodeError) Error() string {
+	return fmt.Sprintf("graph node %q was added more than once", e.Node)
+}
+
+// MissingDependencyError implements the error interface and is returned when a node of a Graph depends on a node that was never added
+type MissingDependencyError struct {
+	Node       string
+	Dependency string
+}
+
+// Error returns an error message for MissingDependencyError
+func (e MissingDependencyError) Error() string {
+	return fmt.Sprintf("graph node %q depends on unknown node %q", e.Node, e.Dependency)
+}
+
+// CycleError implements the error interface and is returned when the dependencies of the nodes of a Graph form a cycle. Nodes contains every node that is part of or depends on a cycle.
+type CycleError struct {
+	Nodes []string
+}
+
+// Error returns an error message for CycleError
+func (e CycleError) Error() string {
+	return fmt.Sprintf("graph contains a dependency cycle between nodes %s", strings.Join(e.Nodes, ", "))
+}
+
+// GraphError implements the error interface and is returned when nodes of a Graph fail. Failed contains the error of each failed node and Skipped the nodes that were not executed because a node they depend on failed.
+type GraphError struct {
+	Failed  map[string]error
+	Skipped []string
+}
+
+// Error returns an error message for GraphError
+func (e GraphError) Error() string {
+	failed := make([]string, 0, len(e.Failed))
+	for name, err := range e.Failed {
+		failed = append(failed, fmt.Sprintf("%s: %s", name, err))
+	}
+	sort.Strings(failed)
+	return fmt.Sprintf("graph nodes failed (%s) and %d nodes were skipped", strings.Join(failed, "; "), len(e.Skipped))
+}
+
+type graphNode struct {
+	name       string
+	fn         GraphFunc
+	deps       []string
+	dependents []string
+}
+
+type graphResult struct {
+	node  string
+	value Value
+}
+
+// Graph executes named GraphFuncs on a WorkerPool once all of the nodes they depend on have completed
+type Graph struct {
+	nodes []*graphNode
+}
+
+// NewGraph creates an empty Graph
+func NewGraph() *Graph {
+	return &Graph{}
+}
+
+// Add adds a node with the provided name that executes fn once each of the nodes named in deps has completed. Nodes can be added in any order.
+func (g *Graph) Add(name string, fn GraphFunc, deps ...string) *Graph {
+	g.nodes = append(g.nodes, &graphNode{name: name, fn: fn, deps: deps})
+	return g
+}
+
+// validate links each node to its dependents and returns an error if the nodes are not a directed acyclic graph
+func (g *Graph) validate() (map[string]*graphNode, error) {
+	nodes := make(map[string]*graphNode, len(g.nodes))
+	for _, n := range g.nodes {
+		if _, ok := nodes[n.name]; ok {
+			return nil, DuplicateNodeError{n.name}
+		}
+		nodes[n.name] = &graphNode{name: n.name, fn: n.fn, deps: n.deps}
+	}
+	pending := make(map[string]int, len(nodes))
+	var ready []string
+	for _, n := range g.nodes {
+		for _, dep := range n.deps {
+			parent, ok := nodes[dep]
+			if !ok {
+				return nil, MissingDependencyError{n.name, dep}
+			}
+			parent.dependents = append(parent.dependents, n.name)
+		}
+		pending[n.name] = len(n.deps)
+		if len(n.deps) == 0 {
+			ready = append(ready, n.name)
+		}
+	}
+	for len(ready) > 0 {
+		name := ready[0]
+		ready = ready[1:]
+		delete(pending, name)
+		for _, dependent := range nodes[name].dependents {
+			pending[dependent]--
+			if pending[dependent] == 0 {
+				ready = append(ready, dependent)
+			}
+		}
+	}
+	if len(pending) > 0 {
+		cycle := make([]string, 0, len(pending))
+		for name := range pending {
+			cycle = append(cycle, name)
+		}
+		sort.Strings(cycle)
+		return nil, CycleError{cycle}
+	}
+	return nodes, nil
+}
+
+// Run executes every node of the Graph on the provided WorkerPool with each node starting as soon as its dependencies have completed. The returned Future resolves with a map of the results keyed by node name.
+// When a node fails the nodes that depend on it are skipped while the rest of the Graph completes and the Future resolves with a GraphError along with the map of the results of the successful nodes.
+func (g *Graph) Run(wp WorkerPoolInterface) Future {
+	return resolveWithContext(func() (Value, context.Context) {
+		return traced(nil, "futures.Graph", func(context.Context) (interface{}, error) {
+			nodes, err := g.validate()
+			if err != nil {
+				return nil, err
+			}
+			results := make(map[string]interface{}, len(nodes))
+			failures := GraphError{Failed: map[string]error{}}
+			pending := make(map[string]int, len(nodes))
+			out := make(chan graphResult, len(nodes))
+			running := 0
+
+			start := func(n *graphNode) {
+				argv := make(map[string]interface{}, len(n.deps))
+				for _, dep := range n.deps {
+					argv[dep] = results[dep]
+				}
+				running++
+				done := make(chan Value, 1)
+				if !wp.Do(done, func() (interface{}, error) {
+					return n.fn(argv)
+				}) {
+					done <- Value{Error: ClosedWorkerPoolError{}}
+				}
+				go func() {
+					out <- graphResult{n.name, <-done}
+				}()
+			}
+			var skip func(n *graphNode)
+			skip = func(n *graphNode) {
+				for _, dependent := range n.dependents {
+					if pending[dependent] >= 0 {
+						pending[dependent] = -1
+						failures.Skipped = append(failures.Skipped, dependent)
+						skip(nodes[dependent])
+					}
+				}
+			}
+
+			for _, n := range g.nodes {
+				pending[n.name] = len(n.deps)
+			}
+			for _, n := range g.nodes {
+				if len(n.deps) == 0 {
+					start(nodes[n.name])
+				}
+			}
+			for running > 0 {
+				result := <-out
+				running--
+				n := nodes[result.node]
+				if result.value.Error != nil {
+					failures.Failed[n.name] = result.value.Error
+					skip(n)
+					continue
+				}
+				results[n.name] = result.value.Data
+				for _, dependent := range n.dependents {
+					if pending[dependent] > 0 {
+						pending[dependent]--
+						if pending[dependent] == 0 {
+							start(nodes[dependent])
+						}
+					}
+				}
+			}
+			if len(failures.Failed) > 0 {
+				sort.Strings(failures.Skipped)
+				return results, failures
+			}
+			return results, nil
+		})
+	})
+}
@@ -0,0 +1,103 @@
+package futures
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGraph(t *testing.T) {
+	wp := NewFuturesWorkerPool(4)
+	defer wp.Close()
+
+	var lock sync.Mutex
+	var order []string
+	node := func(name string, fn func(map[string]interface{}) (interface{}, error)) GraphFunc {
+		return func(deps map[string]interface{}) (interface{}, error) {
+			lock.Lock()
+			order = append(order, name)
+			lock.Unlock()
+			return fn(deps)
+		}
+	}
+	value := <-NewGraph().
+		Add("link", node("link", func(deps map[string]interface{}) (interface{}, error) {
+			return fmt.Sprintf("%v+%v", deps["compile"], deps["assets"]), nil
+		}), "compile", "assets").
+		Add("compile", node("compile", func(deps map[string]interface{}) (interface{}, error) {
+			return fmt.Sprintf("bin(%v)", deps["fetch"]), nil
+		}), "fetch").
+		Add("assets", node("assets", func(deps map[string]interface{}) (interface{}, error) {
+			return fmt.Sprintf("css(%v)", deps["fetch"]), nil
+		}), "fetch").
+		Add("fetch", node("fetch", func(deps map[string]interface{}) (interface{}, error) {
+			assert.Empty(t, deps, "should not pass results to a node without dependencies")
+			return "src", nil
+		})).
+		Run(wp)
+	assert.NoError(t, value.Error)
+	assert.Equal(t, map[string]interface{}{
+		"fetch":   "src",
+		"compile": "bin(src)",
+		"assets":  "css(src)",
+		"link":    "bin(src)+css(src)",
+	}, value.Data, "should resolve with the result of each node")
+	assert.Equal(t, "fetch", order[0], "should execute a node before its dependents")
+	assert.Equal(t, "link", order[3], "should execute a node after all of its dependencies")
+
+	started := make(chan struct{}, 2)
+	both := make(chan struct{})
+	parallel := func(deps map[string]interface{}) (interface{}, error) {
+		started <- struct{}{}
+		select {
+		case <-both:
+		case <-time.After(time.Second):
+			return nil, fmt.Errorf("should execute independent nodes in parallel")
+		}
+		return nil, nil
+	}
+	go func() {
+		<-started
+		<-started
+		close(both)
+	}()
+	value = <-NewGraph().Add("a", parallel).Add("b", parallel).Run(wp)
+	assert.NoError(t, value.Error, "should execute independent nodes in parallel")
+
+	value = <-NewGraph().
+		Add("a", func(map[string]interface{}) (interface{}, error) {
+			return nil, fmt.Errorf("some error")
+		}).
+		Add("b", func(map[string]interface{}) (interface{}, error) {
+			return "b", nil
+		}).
+		Add("c", func(map[string]interface{}) (interface{}, error) {
+			t.Error("should not execute a node that depends on a failed node")
+			return nil, nil
+		}, "a", "b").
+		Add("d", func(map[string]interface{}) (interface{}, error) {
+			t.Error("should not execute a node that depends on a skipped node")
+			return nil, nil
+		}, "c").
+		Run(wp)
+	assert.Equal(t, GraphError{
+		Failed:  map[string]error{"a": fmt.Errorf("some error")},
+		Skipped: []string{"c", "d"},
+	}, value.Error, "should report failed and skipped nodes")
+	assert.Equal(t, map[string]interface{}{"b": "b"}, value.Data, "should resolve with the results of the successful nodes")
+
+	noop := func(map[string]interface{}) (interface{}, error) {
+		return nil, nil
+	}
+	value = <-NewGraph().Add("a", noop, "c").Add("b", noop, "a").Add("c", noop, "b").Add("d", noop).Run(wp)
+	assert.Equal(t, CycleError{[]string{"a", "b", "c"}}, value.Error, "should detect dependency cycles")
+	value = <-NewGraph().Add("a", noop, "b").Run(wp)
+	assert.Equal(t, MissingDependencyError{"a", "b"}, value.Error, "should detect unknown dependencies")
+	value = <-NewGraph().Add("a", noop).Add("a", noop).Run(wp)
+	assert.Equal(t, DuplicateNodeError{"a"}, value.Error, "should detect duplicate nodes")
+	value = <-NewGraph().Run(wp)
+	assert.Equal(t, map[string]interface{}{}, value.Data, "should resolve an empty Graph with an empty map")
+}
@@ -0,0 +1,75 @@
+package futures
+
+import (
+	"context"
+	"time"
+)
+
+// Hedge returns a Future that executes fn and starts another attempt each time delay passes without a successful result, up to maxAttempts attempts in total. A failed attempt starts the next attempt straight away.
+// The Future resolves with the first successful result or with the error of the last attempt once every attempt has failed.
+func Hedge(fn FutureFunc, delay time.Duration, maxAttempts int) Future {
+	return hedge(nil, withoutContext(fn), delay, maxAttempts, nil)
+}
+
+// HedgeWithContext calls Hedge with a ContextFutureFunc whose context is cancelled for every other attempt once one attempt succeeds
+func HedgeWithContext(ctx context.Context, fn ContextFutureFunc, delay time.Duration, maxAttempts int) Future {
+	return hedge(ctx, fn, delay, maxAttempts, nil)
+}
+
+// HedgeWithWorkerPool calls Hedge but executes each attempt on the provided WorkerPool
+func HedgeWithWorkerPool(fn FutureFunc, delay time.Duration, maxAttempts int, wp WorkerPoolInterface) Future {
+	return hedge(nil, withoutContext(fn), delay, maxAttempts, wp)
+}
+
+func hedge(ctx context.Context, fn ContextFutureFunc, delay time.Duration, maxAttempts int, wp WorkerPoolInterface) Future {
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	return resolveWithContext(func() (Value, context.Context) {
+		return traced(ctx, "futures.Hedge", func(ctx context.Context) (interface{}, error) {
+			ctx, cancel := context.WithCancel(ctx)
+			defer cancel()
+			results := make(chan Value, maxAttempts)
+			started := 0
+			launch := func() {
+				started++
+				attempt := func() (interface{}, error) {
+					return fn(ctx)
+				}
+				if wp == nil {
+					go func() {
+						data, err := attempt()
+						results <- Value{Data: data, Error: err}
+					}()
+				} else if !wp.Do(results, attempt) {
+					results <- Value{Error: ClosedWorkerPoolError{}}
+				}
+			}
+
+			launch()
+			timer := time.NewTimer(delay)
+			defer timer.Stop()
+			failed := 0
+			for {
+				select {
+				case v := <-results:
+					if v.Error == nil {
+						return v.Data, nil
+					}
+					failed++
+					if failed == maxAttempts {
+						return nil, v.Error
+					}
+					if started < maxAttempts {
+						launch()
+					}
+				case <-timer.C:
+					if started < maxAttempts {
+						launch()
+						timer.Reset(delay)
+					}
+				}
+			}
+		})
+	})
+}
@@ -0,0 +1,55 @@
+package futures
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHedge(t *testing.T) {
+	var attempts int32
+	slowFirst := func() (interface{}, error) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			time.Sleep(200 * time.Millisecond)
+			return "slow", nil
+		}
+		return "fast", nil
+	}
+	start := time.Now()
+	value := <-Hedge(slowFirst, 10*time.Millisecond, 3)
+	assert.Equal(t, "fast", value.Data, "should resolve with the first successful attempt")
+	assert.True(t, time.Since(start) < 100*time.Millisecond, "should not wait for the slow attempt")
+	assert.Equal(t, int32(2), atomic.LoadInt32(&attempts), "should only start another attempt once the delay has passed")
+
+	atomic.StoreInt32(&attempts, 0)
+	value = <-Hedge(func() (interface{}, error) {
+		return nil, fmt.Errorf("attempt %d failed", atomic.AddInt32(&attempts, 1))
+	}, time.Hour, 3)
+	assert.EqualError(t, value.Error, "attempt 3 failed", "should start the next attempt straight away when an attempt fails and resolve with the last error")
+
+	var cancelled int32
+	atomic.StoreInt32(&attempts, 0)
+	value = <-HedgeWithContext(context.Background(), func(ctx context.Context) (interface{}, error) {
+		if atomic.AddInt32(&attempts, 1) == 3 {
+			return "foobar", nil
+		}
+		<-ctx.Done()
+		atomic.AddInt32(&cancelled, 1)
+		return nil, ctx.Err()
+	}, time.Millisecond, 3)
+	assert.Equal(t, "foobar", value.Data, "should resolve with the first successful attempt")
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt32(&cancelled) == 2
+	}, time.Second, 10*time.Millisecond, "should cancel the other attempts once one succeeds")
+
+	wp := NewFuturesWorkerPool(2)
+	defer wp.Close()
+	atomic.StoreInt32(&attempts, 0)
+	value = <-HedgeWithWorkerPool(slowFirst, 10*time.Millisecond, 2, wp)
+	assert.Equal(t, "fast", value.Data, "should execute attempts on the WorkerPool")
+	assert.Equal(t, uint64(2), wp.Stats().Submitted, "should send each attempt to the WorkerPool")
+}
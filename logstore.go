@@ -0,0 +1,229 @@
+package futures
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"os"
+	"sync"
+)
+
+const (
+	logPut byte = iota + 1
+	logDelete
+)
+
+// logHeader is the size of the header of each record of a LogStore: a CRC32 checksum, the operation and the lengths of the key and the data
+const logHeader = 4 + 1 + 4 + 4
+
+// logCompactSize is how many bytes of overwritten and deleted checkpoints a LogStore keeps before it compacts its file
+var logCompactSize int64 = 1 << 20
+
+// ErrLogStoreClosed is returned by the methods of a LogStore once it has been closed
+var ErrLogStoreClosed = errors.New("log store has been closed")
+
+// logEntry is where the data of a checkpoint starts in the file of a LogStore and how long it is
+type logEntry struct {
+	offset int64
+	size   int
+}
+
+// LogStore is a CheckpointStore that keeps every checkpoint in a single file, in the style of an embedded database such as BoltDB. Saves and deletes are appended to the file as checksummed
+// records and synced to disk before they return, and an index of the file is kept in memory so loads take a single read. Once enough of the file is taken up by overwritten and deleted
+// checkpoints it is compacted by copying the current checkpoints to a new file that replaces it. Only one LogStore at a time may open a file.
+type LogStore struct {
+	lock  sync.RWMutex
+	path  string
+	file  *os.File
+	size  int64
+	dead  int64
+	index map[string]logEntry
+}
+
+// NewLogStore opens the LogStore saved at path and creates it if it does not exist. A record that was only partially written when a process crashed is discarded.
+func NewLogStore(path string) (*LogStore, error) {
+	s := &LogStore{path: path}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// open reads every record of the file to rebuild the index and truncates the file after the last complete record
+func (s *LogStore) open() error {
+	file, err := os.OpenFile(s.path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	s.file, s.size, s.dead, s.index = file, 0, 0, map[string]logEntry{}
+	r := bufio.NewReader(file)
+	header := make([]byte, logHeader)
+	for {
+		if _, err := io.ReadFull(r, header); err != nil {
+			break
+		}
+		keySize, dataSize := binary.BigEndian.Uint32(header[5:9]), binary.BigEndian.Uint32(header[9:13])
+		if s.size+logHeader+int64(keySize)+int64(dataSize) > info.Size() {
+			break
+		}
+		body := make([]byte, int(keySize)+int(dataSize))
+		if _, err := io.ReadFull(r, body); err != nil {
+			break
+		}
+		checksum := crc32.NewIEEE()
+		checksum.Write(header[4:])
+		checksum.Write(body)
+		if checksum.Sum32() != binary.BigEndian.Uint32(header[:4]) {
+			break
+		}
+		s.apply(header[4], string(body[:keySize]), s.size+logHeader+int64(keySize), int(dataSize))
+	}
+	if err := file.Truncate(s.size); err != nil {
+		file.Close()
+		return err
+	}
+	return nil
+}
+
+// apply updates the index with a record of op for key that was written at the end of the file
+func (s *LogStore) apply(op byte, key string, offset int64, size int) {
+	record := offset + int64(size) - s.size
+	if old, ok := s.index[key]; ok {
+		s.dead += logHeader + int64(len(key)) + int64(old.size)
+	}
+	if op == logPut {
+		s.index[key] = logEntry{offset, size}
+	} else {
+		delete(s.index, key)
+		s.dead += record
+	}
+	s.size += record
+}
+
+// append writes a record to the end of the file and syncs it to disk
+func (s *LogStore) append(op byte, key string, data []byte) error {
+	record := make([]byte, logHeader+len(key)+len(data))
+	record[4] = op
+	binary.BigEndian.PutUint32(record[5:9], uint32(len(key)))
+	binary.BigEndian.PutUint32(record[9:13], uint32(len(data)))
+	copy(record[logHeader:], key)
+	copy(record[logHeader+len(key):], data)
+	binary.BigEndian.PutUint32(record[:4], crc32.ChecksumIEEE(record[4:]))
+	if _, err := s.file.WriteAt(record, s.size); err != nil {
+		s.file.Truncate(s.size)
+		return err
+	}
+	if err := s.file.Sync(); err != nil {
+		return err
+	}
+	s.apply(op, key, s.size+logHeader+int64(len(key)), len(data))
+	if s.dead > logCompactSize && s.dead > s.size/2 {
+		return s.compact()
+	}
+	return nil
+}
+
+// Load returns the checkpoint saved for key and false if there is none
+func (s *LogStore) Load(key string) ([]byte, bool, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	if s.file == nil {
+		return nil, false, ErrLogStoreClosed
+	}
+	entry, ok := s.index[key]
+	if !ok {
+		return nil, false, nil
+	}
+	data := make([]byte, entry.size)
+	if _, err := s.file.ReadAt(data, entry.offset); err != nil {
+		return nil, false, err
+	}
+	return data, true, nil
+}
+
+// Save appends the checkpoint to the file and returns once it has been synced to disk
+func (s *LogStore) Save(key string, data []byte) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.file == nil {
+		return ErrLogStoreClosed
+	}
+	return s.append(logPut, key, data)
+}
+
+// Delete removes the checkpoint saved for key
+func (s *LogStore) Delete(key string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.file == nil {
+		return ErrLogStoreClosed
+	}
+	if _, ok := s.index[key]; !ok {
+		return nil
+	}
+	return s.append(logDelete, key, nil)
+}
+
+// Compact rewrites the file with only the current checkpoints. A crash while compacting leaves the previous file in place.
+func (s *LogStore) Compact() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.file == nil {
+		return ErrLogStoreClosed
+	}
+	return s.compact()
+}
+
+func (s *LogStore) compact() error {
+	tmp := s.path + ".compact"
+	compacted, err := NewLogStore(tmp)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp)
+	if err := compacted.file.Truncate(0); err != nil {
+		compacted.Close()
+		return err
+	}
+	compacted.size, compacted.dead, compacted.index = 0, 0, map[string]logEntry{}
+	for key, entry := range s.index {
+		data := make([]byte, entry.size)
+		if _, err := s.file.ReadAt(data, entry.offset); err != nil {
+			compacted.Close()
+			return err
+		}
+		if err := compacted.append(logPut, key, data); err != nil {
+			compacted.Close()
+			return err
+		}
+	}
+	if err := compacted.Close(); err != nil {
+		return err
+	}
+	s.file.Close()
+	renamed := os.Rename(tmp, s.path)
+	if err := s.open(); err != nil {
+		s.file = nil
+		return err
+	}
+	return renamed
+}
+
+// Close closes the file of the LogStore
+func (s *LogStore) Close() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
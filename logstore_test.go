@@ -0,0 +1,83 @@
+package futures
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "futures-logstore")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "checkpoints.db")
+
+	store, err := NewLogStore(path)
+	assert.NoError(t, err)
+	_, ok, err := store.Load("run-1/0")
+	assert.NoError(t, err)
+	assert.False(t, ok, "should return false for a key without a checkpoint")
+
+	assert.NoError(t, store.Save("run-1/0", []byte("reserved")))
+	assert.NoError(t, store.Save("run-1/1", []byte("charged")))
+	assert.NoError(t, store.Save("run-1/0", []byte("reserved again")))
+	assert.NoError(t, store.Save("run-2/0", []byte("reserved")))
+	assert.NoError(t, store.Delete("run-2/0"))
+	assert.NoError(t, store.Delete("run-3/0"), "should ignore deleting a key without a checkpoint")
+	data, ok, err := store.Load("run-1/0")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []byte("reserved again"), data, "should load the last saved checkpoint")
+	assert.NoError(t, store.Close())
+	assert.Equal(t, ErrLogStoreClosed, store.Save("run-1/0", nil), "should return an error once closed")
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0644)
+	assert.NoError(t, err)
+	file.Write([]byte{0, 1, 2, 3, logPut, 0, 0, 0, 7})
+	file.Close()
+
+	store, err = NewLogStore(path)
+	assert.NoError(t, err)
+	data, _, _ = store.Load("run-1/0")
+	assert.Equal(t, []byte("reserved again"), data, "should keep checkpoints once reopened")
+	data, _, _ = store.Load("run-1/1")
+	assert.Equal(t, []byte("charged"), data, "should keep checkpoints once reopened")
+	_, ok, _ = store.Load("run-2/0")
+	assert.False(t, ok, "should keep deletes once reopened")
+	assert.NoError(t, store.Save("run-1/2", []byte("shipped")), "should discard a partially written record")
+	data, _, _ = store.Load("run-1/2")
+	assert.Equal(t, []byte("shipped"), data)
+
+	before, _ := os.Stat(path)
+	assert.NoError(t, store.Compact())
+	after, _ := os.Stat(path)
+	assert.True(t, after.Size() < before.Size(), "should remove overwritten and deleted checkpoints when compacting")
+	data, _, _ = store.Load("run-1/0")
+	assert.Equal(t, []byte("reserved again"), data, "should keep the current checkpoints when compacting")
+
+	size := logCompactSize
+	logCompactSize = 64
+	defer func() {
+		logCompactSize = size
+	}()
+	for i := 0; i < 100; i++ {
+		assert.NoError(t, store.Save("run-1/1", []byte("charged")))
+	}
+	info, _ := os.Stat(path)
+	assert.True(t, info.Size() < 256, "should compact once enough of the file is overwritten checkpoints")
+	assert.NoError(t, store.Close())
+
+	store, err = NewLogStore(path)
+	assert.NoError(t, err)
+	defer store.Close()
+	value := <-NewWorkflow("order", store).
+		Step("reserve", func(key string, v interface{}) (interface{}, error) {
+			return v.(int) + 1, nil
+		}).
+		Run("run-4", 1)
+	assert.NoError(t, value.Error)
+	assert.Equal(t, 2, value.Data, "should checkpoint a Workflow")
+}
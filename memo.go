@@ -0,0 +1,153 @@
+package futures
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoOption configures optional caching behavior of a Memoizer created with NewMemoizer
+type MemoOption func(*Memoizer)
+
+// WithTTL caches the resolved Value of each key for the provided duration
+func WithTTL(ttl time.Duration) MemoOption {
+	return func(m *Memoizer) {
+		m.ttl = ttl
+	}
+}
+
+// WithMaxEntries caches the resolved Values of up to max keys and evicts the least recently used key once the limit is reached
+func WithMaxEntries(max int) MemoOption {
+	return func(m *Memoizer) {
+		m.maxEntries = max
+	}
+}
+
+// WithErrorCaching caches Values that resolved with an error which are otherwise executed again on the next call
+func WithErrorCaching() MemoOption {
+	return func(m *Memoizer) {
+		m.cacheErrors = true
+	}
+}
+
+// Memoizer deduplicates concurrent executions of FutureFuncs by key and optionally caches their resolved Values. Results are only cached when WithTTL or WithMaxEntries is provided.
+type Memoizer struct {
+	lock        sync.Mutex
+	ttl         time.Duration
+	maxEntries  int
+	cacheErrors bool
+	calls       map[interface{}]*memoCall
+	entries     map[interface{}]*list.Element
+	lru         *list.List
+}
+
+// memoCall is an in-flight execution of a FutureFunc shared by every caller of the same key
+type memoCall struct {
+	done  chan struct{}
+	value Value
+	ctx   context.Context
+}
+
+type memoEntry struct {
+	key     interface{}
+	value   Value
+	expires time.Time
+}
+
+// NewMemoizer returns a Memoizer configured with the provided MemoOptions
+func NewMemoizer(opts ...MemoOption) *Memoizer {
+	m := &Memoizer{
+		calls:   map[interface{}]*memoCall{},
+		entries: map[interface{}]*list.Element{},
+		lru:     list.New(),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+var defaultMemoizer = NewMemoizer()
+
+// Memo returns a Future that resolves with the Value of fn. Concurrent calls with the same key share a single execution of fn and no results are cached. Use NewMemoizer to cache results.
+func Memo(key interface{}, fn FutureFunc) Future {
+	return defaultMemoizer.Memo(key, fn)
+}
+
+// Memo returns a Future that resolves with the cached Value of key or with the Value of fn. Concurrent calls with the same key share a single execution of fn.
+func (m *Memoizer) Memo(key interface{}, fn FutureFunc) Future {
+	m.lock.Lock()
+	if value, ok := m.cached(key); ok {
+		m.lock.Unlock()
+		return resolve(func() Value {
+			return value
+		})
+	}
+	call, ok := m.calls[key]
+	if !ok {
+		call = &memoCall{done: make(chan struct{})}
+		m.calls[key] = call
+		go m.execute(key, call, fn)
+	}
+	m.lock.Unlock()
+	return resolveWithContext(func() (Value, context.Context) {
+		<-call.done
+		return call.value, call.ctx
+	})
+}
+
+// Forget removes the cached Value of key so that the next call executes its FutureFunc again
+func (m *Memoizer) Forget(key interface{}) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if element, ok := m.entries[key]; ok {
+		m.remove(element)
+	}
+}
+
+func (m *Memoizer) execute(key interface{}, call *memoCall, fn FutureFunc) {
+	call.value, call.ctx = traced(nil, "futures.Memo", func(context.Context) (interface{}, error) {
+		return fn()
+	})
+	m.lock.Lock()
+	delete(m.calls, key)
+	if (m.ttl > 0 || m.maxEntries > 0) && (call.value.Error == nil || m.cacheErrors) {
+		m.store(key, call.value)
+	}
+	m.lock.Unlock()
+	close(call.done)
+}
+
+// cached returns the unexpired Value of key and marks it as the most recently used
+func (m *Memoizer) cached(key interface{}) (Value, bool) {
+	element, ok := m.entries[key]
+	if !ok {
+		return Value{}, false
+	}
+	entry := element.Value.(*memoEntry)
+	if m.ttl > 0 && time.Now().After(entry.expires) {
+		m.remove(element)
+		return Value{}, false
+	}
+	m.lru.MoveToFront(element)
+	return entry.value, true
+}
+
+func (m *Memoizer) store(key interface{}, value Value) {
+	entry := &memoEntry{key, value, time.Now().Add(m.ttl)}
+	if element, ok := m.entries[key]; ok {
+		element.Value = entry
+		m.lru.MoveToFront(element)
+	} else {
+		m.entries[key] = m.lru.PushFront(entry)
+	}
+	if m.maxEntries > 0 && m.lru.Len() > m.maxEntries {
+		m.remove(m.lru.Back())
+	}
+}
+
+func (m *Memoizer) remove(element *list.Element) {
+	m.lru.Remove(element)
+	delete(m.entries, element.Value.(*memoEntry).key)
+}
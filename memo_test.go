@@ -0,0 +1,65 @@
+package futures
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemo(t *testing.T) {
+	var calls int32
+	release := make(chan bool)
+	lookup := func() (interface{}, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return "foobar", nil
+	}
+
+	var results []Future
+	for i := 0; i < 5; i++ {
+		results = append(results, Memo("key", lookup))
+	}
+	close(release)
+	for _, f := range results {
+		assert.Equal(t, "foobar", (<-f).Data, "should resolve every caller with the shared value")
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls), "should execute concurrent calls with the same key once")
+	<-Memo("key", lookup)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls), "should not cache values by default")
+}
+
+func TestMemoizer(t *testing.T) {
+	var calls int32
+	fn := func(err error) FutureFunc {
+		return func() (interface{}, error) {
+			return atomic.AddInt32(&calls, 1), err
+		}
+	}
+
+	m := NewMemoizer(WithTTL(20*time.Millisecond), WithMaxEntries(2))
+	<-m.Memo("a", fn(nil))
+	assert.Equal(t, int32(1), (<-m.Memo("a", fn(nil))).Data, "should resolve with the cached value")
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, int32(2), (<-m.Memo("a", fn(nil))).Data, "should execute again once the TTL has passed")
+
+	<-m.Memo("b", fn(nil))
+	<-m.Memo("a", fn(nil))
+	<-m.Memo("c", fn(nil))
+	assert.Equal(t, int32(2), (<-m.Memo("a", fn(nil))).Data, "should keep recently used values")
+	assert.Equal(t, int32(5), (<-m.Memo("b", fn(nil))).Data, "should evict the least recently used value")
+
+	m.Forget("a")
+	assert.Equal(t, int32(6), (<-m.Memo("a", fn(nil))).Data, "should execute again once a key is forgotten")
+
+	<-m.Memo("error", fn(fmt.Errorf("some error")))
+	assert.Equal(t, int32(8), (<-m.Memo("error", fn(fmt.Errorf("some error")))).Data, "should not cache errors by default")
+
+	m = NewMemoizer(WithMaxEntries(1), WithErrorCaching())
+	<-m.Memo("error", fn(fmt.Errorf("some error")))
+	value := <-m.Memo("error", fn(nil))
+	assert.Equal(t, int32(9), value.Data, "should cache errors when opted in")
+	assert.EqualError(t, value.Error, "some error", "should resolve with the cached error")
+}
@@ -0,0 +1,199 @@
+package futures
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultLatencyBuckets are the upper bounds of the task latency histograms reported by WorkerPool Stats and match the default Prometheus histogram buckets
+var DefaultLatencyBuckets = []time.Duration{
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+// LatencyHistogram contains the distribution of FutureFunc execution times. Counts are cumulative so that Counts[i] is the number of executions that took at most Bounds[i].
+type LatencyHistogram struct {
+	Bounds []time.Duration
+	Counts []uint64
+	Count  uint64
+	Sum    time.Duration
+}
+
+// WorkerPoolStats is a snapshot of the health of a WorkerPool or NestedWorkerPool. Stats for a WorkerPool include the stats of each of its open NestedWorkerPools.
+type WorkerPoolStats struct {
+	Name        string
+	Workers     int
+	BusyWorkers int
+	QueueDepth  int
+	Submitted   uint64
+	Completed   uint64
+	Failed      uint64
+	Throughput  float64
+	ErrorRate   float64
+	Latency     LatencyHistogram
+	Nested      []WorkerPoolStats
+}
+
+// poolMetrics collects the counters for a single WorkerPool or NestedWorkerPool
+type poolMetrics struct {
+	name      string
+	created   time.Time
+	lock      sync.Mutex
+	queued    int
+	active    int
+	submitted uint64
+	completed uint64
+	failed    uint64
+	buckets   []uint64
+	sum       time.Duration
+}
+
+func newPoolMetrics(name string) *poolMetrics {
+	return &poolMetrics{
+		name:    name,
+		created: time.Now(),
+		buckets: make([]uint64, len(DefaultLatencyBuckets)),
+	}
+}
+
+func (m *poolMetrics) submit() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.queued++
+	m.submitted++
+}
+
+// withdraw reverts submit for a task that was never queued
+func (m *poolMetrics) withdraw() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.queued--
+	m.submitted--
+}
+
+func (m *poolMetrics) start() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.queued--
+	m.active++
+}
+
+func (m *poolMetrics) finish(latency time.Duration, err error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.active--
+	m.completed++
+	if err != nil {
+		m.failed++
+	}
+	m.sum += latency
+	for i, bound := range DefaultLatencyBuckets {
+		if latency <= bound {
+			m.buckets[i]++
+			break
+		}
+	}
+}
+
+func (m *poolMetrics) snapshot() WorkerPoolStats {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	stats := WorkerPoolStats{
+		Name:        m.name,
+		BusyWorkers: m.active,
+		QueueDepth:  m.queued,
+		Submitted:   m.submitted,
+		Completed:   m.completed,
+		Failed:      m.failed,
+		Latency: LatencyHistogram{
+			Bounds: DefaultLatencyBuckets,
+			Counts: make([]uint64, len(m.buckets)),
+			Count:  m.completed,
+			Sum:    m.sum,
+		},
+	}
+	var cumulative uint64
+	for i, count := range m.buckets {
+		cumulative += count
+		stats.Latency.Counts[i] = cumulative
+	}
+	if elapsed := time.Since(m.created).Seconds(); elapsed > 0 {
+		stats.Throughput = float64(m.completed) / elapsed
+	}
+	if m.completed > 0 {
+		stats.ErrorRate = float64(m.failed) / float64(m.completed)
+	}
+	return stats
+}
+
+// WritePrometheus writes the provided WorkerPoolStats and the stats of its NestedWorkerPools to w in the Prometheus text exposition format with each metric name prefixed by namespace
+func WritePrometheus(w io.Writer, namespace string, stats WorkerPoolStats) error {
+	pools := append([]WorkerPoolStats{stats}, stats.Nested...)
+	var b strings.Builder
+	gauge := func(name, help string, value func(WorkerPoolStats) float64) {
+		fmt.Fprintf(&b, "# HELP %s_%s %s\n# TYPE %s_%s gauge\n", namespace, name, help, namespace, name)
+		for _, p := range pools {
+			fmt.Fprintf(&b, "%s_%s{pool=%q} %g\n", namespace, name, p.Name, value(p))
+		}
+	}
+	counter := func(name, help string, value func(WorkerPoolStats) uint64) {
+		fmt.Fprintf(&b, "# HELP %s_%s %s\n# TYPE %s_%s counter\n", namespace, name, help, namespace, name)
+		for _, p := range pools {
+			fmt.Fprintf(&b, "%s_%s{pool=%q} %d\n", namespace, name, p.Name, value(p))
+		}
+	}
+
+	gauge("workerpool_workers", "Number of worker go routines.", func(p WorkerPoolStats) float64 {
+		return float64(p.Workers)
+	})
+	gauge("workerpool_busy_workers", "Number of FutureFuncs currently executing.", func(p WorkerPoolStats) float64 {
+		return float64(p.BusyWorkers)
+	})
+	gauge("workerpool_queue_depth", "Number of FutureFuncs waiting to be executed.", func(p WorkerPoolStats) float64 {
+		return float64(p.QueueDepth)
+	})
+	counter("workerpool_tasks_submitted_total", "Total number of FutureFuncs submitted.", func(p WorkerPoolStats) uint64 {
+		return p.Submitted
+	})
+	counter("workerpool_tasks_completed_total", "Total number of FutureFuncs executed.", func(p WorkerPoolStats) uint64 {
+		return p.Completed
+	})
+	counter("workerpool_tasks_failed_total", "Total number of FutureFuncs that returned an error.", func(p WorkerPoolStats) uint64 {
+		return p.Failed
+	})
+
+	name := namespace + "_workerpool_task_duration_seconds"
+	fmt.Fprintf(&b, "# HELP %s Execution time of FutureFuncs.\n# TYPE %s histogram\n", name, name)
+	for _, p := range pools {
+		for i, bound := range p.Latency.Bounds {
+			fmt.Fprintf(&b, "%s_bucket{pool=%q,le=\"%g\"} %d\n", name, p.Name, bound.Seconds(), p.Latency.Counts[i])
+		}
+		fmt.Fprintf(&b, "%s_bucket{pool=%q,le=\"+Inf\"} %d\n", name, p.Name, p.Latency.Count)
+		fmt.Fprintf(&b, "%s_sum{pool=%q} %g\n", name, p.Name, p.Latency.Sum.Seconds())
+		fmt.Fprintf(&b, "%s_count{pool=%q} %d\n", name, p.Name, p.Latency.Count)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// NewPrometheusHandler returns an http.Handler that serves the Stats of the provided WorkerPoolInterface in the Prometheus text exposition format
+func NewPrometheusHandler(wp WorkerPoolInterface, namespace string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		WritePrometheus(w, namespace, wp.Stats())
+	})
+}
@@ -0,0 +1,64 @@
+package futures
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkerPoolStats(t *testing.T) {
+	wp := NewFuturesWorkerPool(2)
+	defer wp.Close()
+	np := wp.Fork(1)
+
+	wp.Send(func() (interface{}, error) {
+		return "foobar", nil
+	})
+	wp.Receive()
+	np.Send(func() (interface{}, error) {
+		return nil, fmt.Errorf("some error")
+	})
+	np.Receive()
+
+	stats := wp.Stats()
+	assert.Equal(t, "root", stats.Name, "should name the parent WorkerPool stats")
+	assert.Equal(t, 2, stats.Workers, "should report the number of workers")
+	assert.Equal(t, uint64(2), stats.Submitted, "should count FutureFuncs sent to the WorkerPool and its NestedWorkerPools")
+	assert.Equal(t, uint64(2), stats.Completed, "should count executed FutureFuncs")
+	assert.Equal(t, uint64(1), stats.Failed, "should count FutureFuncs that returned an error")
+	assert.Equal(t, 0.5, stats.ErrorRate, "should report the error rate")
+	assert.Equal(t, uint64(2), stats.Latency.Count, "should record the latency of executed FutureFuncs")
+	assert.Equal(t, uint64(2), stats.Latency.Counts[len(stats.Latency.Counts)-1], "should report cumulative latency bucket counts")
+
+	assert.Len(t, stats.Nested, 1, "should break stats down per NestedWorkerPool")
+	assert.Equal(t, uint64(1), stats.Nested[0].Submitted, "should only count FutureFuncs sent to the NestedWorkerPool")
+	assert.Equal(t, uint64(1), stats.Nested[0].Failed, "should count failed FutureFuncs sent to the NestedWorkerPool")
+	assert.Equal(t, stats.Nested[0].Name, np.Stats().Name, "should return the same stats from the NestedWorkerPool")
+
+	np.Close()
+	assert.Eventually(t, func() bool {
+		return len(wp.Stats().Nested) == 0
+	}, time.Second, 10*time.Millisecond, "should stop reporting stats for closed NestedWorkerPools")
+}
+
+func TestPrometheusHandler(t *testing.T) {
+	wp := NewFuturesWorkerPool(1)
+	defer wp.Close()
+	wp.Send(func() (interface{}, error) {
+		return nil, nil
+	})
+	wp.Receive()
+
+	recorder := httptest.NewRecorder()
+	NewPrometheusHandler(wp, "test").ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	body, _ := ioutil.ReadAll(recorder.Body)
+
+	assert.Contains(t, string(body), "# TYPE test_workerpool_workers gauge\ntest_workerpool_workers{pool=\"root\"} 1\n", "should export gauges")
+	assert.Contains(t, string(body), "test_workerpool_tasks_completed_total{pool=\"root\"} 1\n", "should export counters")
+	assert.Contains(t, string(body), "test_workerpool_task_duration_seconds_bucket{pool=\"root\",le=\"+Inf\"} 1\n", "should export latency histograms")
+	assert.Contains(t, string(body), "test_workerpool_task_duration_seconds_count{pool=\"root\"} 1\n", "should export latency histogram counts")
+}
@@ -0,0 +1,565 @@
+package futures
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ObservableFunc specifies the function signature for producing the values of an Observable. Each value is pushed to the subscriber with emit which returns false once the subscriber no longer accepts values, at which point the ObservableFunc should return.
+// Returning an error terminates the Observable with the error and returning nil completes it.
+type ObservableFunc func(ctx context.Context, emit func(interface{}) bool) error
+
+// Observable is a push-based stream of values. Observables created with NewObservable are cold and execute their ObservableFunc for every subscription while Observables returned by Share are hot and multicast a single execution to all of their current subscribers.
+type Observable struct {
+	fn ObservableFunc
+}
+
+// BackpressureStrategy specifies what happens to the values of an Observable when the buffer of a subscriber is full
+type BackpressureStrategy int
+
+const (
+	// BackpressureBlock blocks the Observable until the subscriber has received a value
+	BackpressureBlock BackpressureStrategy = iota
+	// BackpressureDropNewest discards values emitted while the buffer is full
+	BackpressureDropNewest
+	// BackpressureDropOldest discards the oldest buffered value to make room for a new value
+	BackpressureDropOldest
+	// BackpressureFail terminates the subscription with a BackpressureError once the buffer is full
+	BackpressureFail
+)
+
+// BackpressureError implements the error interface and is returned for a subscription with the BackpressureFail strategy whose buffer was full
+type BackpressureError struct {
+	Buffer int
+}
+
+// Error returns an error message for BackpressureError
+func (e BackpressureError) Error() string {
+	return fmt.Sprintf("observable subscriber buffer of %d values overflowed", e.Buffer)
+}
+
+// SubscribeOption configures a subscription created with Observable.Subscribe
+type SubscribeOption func(*subscription)
+
+type subscription struct {
+	buffer   int
+	strategy BackpressureStrategy
+}
+
+// WithBackpressure buffers up to buffer values for a subscriber and applies the strategy once the buffer is full. Every strategy other than BackpressureBlock requires a buffer of at least one value.
+func WithBackpressure(buffer int, strategy BackpressureStrategy) SubscribeOption {
+	return func(s *subscription) {
+		s.buffer = buffer
+		s.strategy = strategy
+	}
+}
+
+// NewObservable returns a cold Observable which executes the provided ObservableFunc for each of its subscribers
+func NewObservable(fn ObservableFunc) Observable {
+	return Observable{fn}
+}
+
+// Subscribe executes the Observable and returns a channel that receives each of its values. If the Observable terminates with an error it is sent as the last Value before the channel is closed.
+// The subscription ends and the channel is closed once ctx is done.
+func (o Observable) Subscribe(ctx context.Context, opts ...SubscribeOption) <-chan Value {
+	s := &subscription{}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.strategy != BackpressureBlock && s.buffer < 1 {
+		s.buffer = 1
+	}
+	out := make(chan Value, s.buffer)
+	ctx, cancel := context.WithCancel(ctx)
+	go func() {
+		defer close(out)
+		defer cancel()
+		var overflow error
+		err := o.fn(ctx, func(v interface{}) bool {
+			if ctx.Err() != nil {
+				return false
+			}
+			value := Value{Data: v}
+			switch s.strategy {
+			case BackpressureDropNewest:
+				select {
+				case out <- value:
+				default:
+				}
+				return true
+			case BackpressureDropOldest:
+				for {
+					select {
+					case out <- value:
+						return true
+					default:
+					}
+					select {
+					case <-out:
+					default:
+					}
+				}
+			case BackpressureFail:
+				select {
+				case out <- value:
+					return true
+				default:
+					overflow = BackpressureError{s.buffer}
+					return false
+				}
+			}
+			select {
+			case out <- value:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		})
+		if overflow != nil {
+			err = overflow
+		}
+		if err != nil {
+			select {
+			case out <- Value{Error: err}:
+			case <-ctx.Done():
+			}
+		}
+	}()
+	return out
+}
+
+// ToFuture returns a Future that executes the Observable and resolves with its last value or the error it terminated with
+func (o Observable) ToFuture() Future {
+	return resolveWithContext(func() (Value, context.Context) {
+		return traced(nil, "futures.Observable.ToFuture", func(ctx context.Context) (interface{}, error) {
+			var last interface{}
+			err := o.fn(ctx, func(v interface{}) bool {
+				last = v
+				return true
+			})
+			return last, err
+		})
+	})
+}
+
+// FromFuture returns an Observable that emits the resolved data of the Future or terminates with its error. The Future is only read once and its Value is shared by every subscriber.
+func FromFuture(f Future) Observable {
+	var once sync.Once
+	var value Value
+	resolved := make(chan struct{})
+	return NewObservable(func(ctx context.Context, emit func(interface{}) bool) error {
+		once.Do(func() {
+			go func() {
+				var err error
+				if value, err = f.resolveLast(); err != nil {
+					value = Value{Error: err}
+				}
+				close(resolved)
+			}()
+		})
+		select {
+		case <-resolved:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		if value.Error != nil {
+			return value.Error
+		}
+		emit(value.Data)
+		return nil
+	})
+}
+
+// FromGenerator returns an Observable that emits the values yielded by the Generator and terminates with the first error. Since the values of a Generator can only be received once it should only be subscribed to once or made hot with Share.
+// The Generator is closed when the subscriber stops receiving values before it is done.
+func FromGenerator(gen Generator) Observable {
+	return NewObservable(func(ctx context.Context, emit func(interface{}) bool) error {
+		next := pull(gen)
+		for {
+			v, ok := next()
+			if !ok {
+				return nil
+			}
+			if v.Error != nil {
+				gen.Close()
+				return v.Error
+			}
+			if !emit(v.Value) {
+				gen.Close()
+				return nil
+			}
+		}
+	})
+}
+
+// Map returns an Observable that emits the result of calling fn with each value and terminates with the first error returned by fn
+func (o Observable) Map(fn ThenableFunc) Observable {
+	return NewObservable(func(ctx context.Context, emit func(interface{}) bool) error {
+		var failed error
+		err := o.fn(ctx, func(v interface{}) bool {
+			result, err := fn(v)
+			if err != nil {
+				failed = err
+				return false
+			}
+			return emit(result)
+		})
+		if failed != nil {
+			return failed
+		}
+		return err
+	})
+}
+
+// Filter returns an Observable that only emits the values for which fn returns true
+func (o Observable) Filter(fn PredicateFunc) Observable {
+	return NewObservable(func(ctx context.Context, emit func(interface{}) bool) error {
+		return o.fn(ctx, func(v interface{}) bool {
+			return !fn(v) || emit(v)
+		})
+	})
+}
+
+// Throttle returns an Observable that emits a value and then discards the values that follow it within the interval
+func (o Observable) Throttle(interval time.Duration) Observable {
+	return NewObservable(func(ctx context.Context, emit func(interface{}) bool) error {
+		var last time.Time
+		return o.fn(ctx, func(v interface{}) bool {
+			now := time.Now()
+			if !last.IsZero() && now.Sub(last) < interval {
+				return true
+			}
+			last = now
+			return emit(v)
+		})
+	})
+}
+
+// pump executes the Observable in a separate go routine and sends its values to the returned channel which is closed once the Observable returns. The stop function cancels the execution and waits for it to return.
+func (o Observable) pump(ctx context.Context) (<-chan interface{}, <-chan error, func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	values := make(chan interface{})
+	errc := make(chan error, 1)
+	go func() {
+		defer close(values)
+		errc <- o.fn(ctx, func(v interface{}) bool {
+			select {
+			case values <- v:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		})
+	}()
+	return values, errc, func() {
+		cancel()
+		for range values {
+		}
+	}
+}
+
+// Debounce returns an Observable that only emits a value once no other value has followed it within the interval. The last value is emitted as soon as the Observable completes.
+func (o Observable) Debounce(interval time.Duration) Observable {
+	return NewObservable(func(ctx context.Context, emit func(interface{}) bool) error {
+		values, errc, stop := o.pump(ctx)
+		defer stop()
+		timer := time.NewTimer(interval)
+		timer.Stop()
+		defer func() {
+			timer.Stop()
+		}()
+		var pending interface{}
+		var fire <-chan time.Time
+		for {
+			select {
+			case v, ok := <-values:
+				if !ok {
+					err := <-errc
+					if fire != nil && err == nil {
+						emit(pending)
+					}
+					return err
+				}
+				pending = v
+				timer.Stop()
+				timer = time.NewTimer(interval)
+				fire = timer.C
+			case <-fire:
+				fire = nil
+				if !emit(pending) {
+					return nil
+				}
+			}
+		}
+	})
+}
+
+// Buffer returns an Observable that emits slices of up to size values. When interval is greater than zero the buffered values are also emitted each time the interval passes. The remaining values are emitted once the Observable completes.
+func (o Observable) Buffer(size int, interval time.Duration) Observable {
+	return NewObservable(func(ctx context.Context, emit func(interface{}) bool) error {
+		values, errc, stop := o.pump(ctx)
+		defer stop()
+		var tick <-chan time.Time
+		if interval > 0 {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			tick = ticker.C
+		}
+		var buffer []interface{}
+		flush := func() bool {
+			if len(buffer) == 0 {
+				return true
+			}
+			full := buffer
+			buffer = nil
+			return emit(full)
+		}
+		for {
+			select {
+			case v, ok := <-values:
+				if !ok {
+					err := <-errc
+					if err == nil {
+						flush()
+					}
+					return err
+				}
+				buffer = append(buffer, v)
+				if size > 0 && len(buffer) >= size && !flush() {
+					return nil
+				}
+			case <-tick:
+				if !flush() {
+					return nil
+				}
+			}
+		}
+	})
+}
+
+// Merge returns an Observable that executes the Observable and all of the other Observables at the same time and emits their values as they arrive. It terminates with the first error and completes once all of them have completed.
+func (o Observable) Merge(others ...Observable) Observable {
+	sources := append([]Observable{o}, others...)
+	return NewObservable(func(ctx context.Context, emit func(interface{}) bool) error {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		var lock sync.Mutex
+		stopped := false
+		errc := make(chan error, len(sources))
+		var wg sync.WaitGroup
+		for _, source := range sources {
+			wg.Add(1)
+			go func(source Observable) {
+				defer wg.Done()
+				err := source.fn(ctx, func(v interface{}) bool {
+					lock.Lock()
+					defer lock.Unlock()
+					if stopped {
+						return false
+					}
+					if !emit(v) {
+						stopped = true
+						cancel()
+						return false
+					}
+					return true
+				})
+				if err != nil {
+					errc <- err
+					cancel()
+				}
+			}(source)
+		}
+		wg.Wait()
+		close(errc)
+		if stopped {
+			return nil
+		}
+		return <-errc
+	})
+}
+
+// Concat returns an Observable that emits all the values of the Observable followed by all the values of each of the other Observables in order
+func (o Observable) Concat(others ...Observable) Observable {
+	sources := append([]Observable{o}, others...)
+	return NewObservable(func(ctx context.Context, emit func(interface{}) bool) error {
+		for _, source := range sources {
+			stopped := false
+			err := source.fn(ctx, func(v interface{}) bool {
+				stopped = !emit(v)
+				return !stopped
+			})
+			if err != nil || stopped {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// SwitchMap returns an Observable that calls fn with each value and emits the values of the returned Observable until the next value arrives, at which point the previous inner Observable is cancelled.
+// It terminates with the first error of the Observable or the current inner Observable and completes once both have completed.
+func (o Observable) SwitchMap(fn func(interface{}) Observable) Observable {
+	return NewObservable(func(ctx context.Context, emit func(interface{}) bool) error {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		var lock sync.Mutex
+		var current int
+		var cancelInner context.CancelFunc
+		stopped := false
+		errc := make(chan error, 1)
+		var wg sync.WaitGroup
+		err := o.fn(ctx, func(v interface{}) bool {
+			inner := fn(v)
+			lock.Lock()
+			if stopped {
+				lock.Unlock()
+				return false
+			}
+			if cancelInner != nil {
+				cancelInner()
+			}
+			current++
+			id := current
+			innerCtx, innerCancel := context.WithCancel(ctx)
+			cancelInner = innerCancel
+			lock.Unlock()
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				err := inner.fn(innerCtx, func(v interface{}) bool {
+					lock.Lock()
+					defer lock.Unlock()
+					if stopped || id != current {
+						return false
+					}
+					if !emit(v) {
+						stopped = true
+						cancel()
+						return false
+					}
+					return true
+				})
+				if err != nil && innerCtx.Err() == nil {
+					select {
+					case errc <- err:
+					default:
+					}
+					cancel()
+				}
+			}()
+			return true
+		})
+		wg.Wait()
+		lock.Lock()
+		if cancelInner != nil {
+			cancelInner()
+		}
+		lock.Unlock()
+		select {
+		case innerErr := <-errc:
+			return innerErr
+		default:
+		}
+		if stopped {
+			return nil
+		}
+		return err
+	})
+}
+
+// Retry returns an Observable that executes the Observable again when it terminates with an error up to the provided number of retries. Values emitted before the error are not emitted again.
+func (o Observable) Retry(retries int) Observable {
+	return NewObservable(func(ctx context.Context, emit func(interface{}) bool) error {
+		for attempt := 0; ; attempt++ {
+			stopped := false
+			err := o.fn(ctx, func(v interface{}) bool {
+				stopped = !emit(v)
+				return !stopped
+			})
+			if err == nil || stopped || ctx.Err() != nil || attempt >= retries {
+				return err
+			}
+		}
+	})
+}
+
+// broadcast is a single execution of the source of a hot Observable along with its current subscribers
+type broadcast struct {
+	subscribers map[*subscriber]struct{}
+	cancel      context.CancelFunc
+}
+
+type subscriber struct {
+	emit func(interface{}) bool
+	done chan error
+}
+
+// Share returns a hot Observable that executes the Observable once for all of its current subscribers. Execution starts with the first subscriber and is cancelled once every subscriber has unsubscribed. Subscribers only receive the values emitted after they subscribed.
+func (o Observable) Share() Observable {
+	var lock sync.Mutex
+	var current *broadcast
+	return NewObservable(func(ctx context.Context, emit func(interface{}) bool) error {
+		s := &subscriber{emit, make(chan error, 1)}
+		lock.Lock()
+		b := current
+		if b == nil {
+			var sourceCtx context.Context
+			b = &broadcast{subscribers: map[*subscriber]struct{}{}}
+			sourceCtx, b.cancel = context.WithCancel(context.Background())
+			current = b
+			go func() {
+				err := o.fn(sourceCtx, func(v interface{}) bool {
+					lock.Lock()
+					defer lock.Unlock()
+					for s := range b.subscribers {
+						if !s.emit(v) {
+							delete(b.subscribers, s)
+							s.done <- nil
+						}
+					}
+					if len(b.subscribers) == 0 {
+						if current == b {
+							current = nil
+						}
+						return false
+					}
+					return true
+				})
+				lock.Lock()
+				defer lock.Unlock()
+				for s := range b.subscribers {
+					delete(b.subscribers, s)
+					s.done <- err
+				}
+				if current == b {
+					current = nil
+				}
+				b.cancel()
+			}()
+		}
+		b.subscribers[s] = struct{}{}
+		lock.Unlock()
+
+		select {
+		case err := <-s.done:
+			return err
+		case <-ctx.Done():
+			lock.Lock()
+			defer lock.Unlock()
+			if _, ok := b.subscribers[s]; !ok {
+				return <-s.done
+			}
+			delete(b.subscribers, s)
+			if len(b.subscribers) == 0 {
+				if current == b {
+					current = nil
+				}
+				b.cancel()
+			}
+			return ctx.Err()
+		}
+	})
+}
@@ -0,0 +1,275 @@
+package futures
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func emitAll(values ...interface{}) Observable {
+	return NewObservable(func(ctx context.Context, emit func(interface{}) bool) error {
+		for _, v := range values {
+			if !emit(v) {
+				return nil
+			}
+		}
+		return nil
+	})
+}
+
+func receiveAll(o Observable, opts ...SubscribeOption) ([]interface{}, error) {
+	var results []interface{}
+	for v := range o.Subscribe(context.Background(), opts...) {
+		if v.Error != nil {
+			return results, v.Error
+		}
+		results = append(results, v.Data)
+	}
+	return results, nil
+}
+
+func TestObservable(t *testing.T) {
+	var executions int32
+	cold := NewObservable(func(ctx context.Context, emit func(interface{}) bool) error {
+		atomic.AddInt32(&executions, 1)
+		for i := 1; i <= 3; i++ {
+			if !emit(i) {
+				return nil
+			}
+		}
+		return nil
+	})
+	results, _ := receiveAll(cold)
+	assert.Equal(t, []interface{}{1, 2, 3}, results, "should receive each value")
+	results, _ = receiveAll(cold)
+	assert.Equal(t, []interface{}{1, 2, 3}, results, "should receive each value for every subscription of a cold observable")
+	assert.Equal(t, int32(2), atomic.LoadInt32(&executions), "should execute a cold observable for each subscription")
+
+	results, err := receiveAll(cold.Map(func(v interface{}) (interface{}, error) {
+		if v.(int) == 3 {
+			return nil, fmt.Errorf("some error")
+		}
+		return v.(int) * 10, nil
+	}))
+	assert.Equal(t, []interface{}{10, 20}, results, "should map values")
+	assert.EqualError(t, err, "some error", "should terminate with the error returned by Map")
+
+	results, _ = receiveAll(cold.Filter(func(v interface{}) bool {
+		return v.(int) != 2
+	}))
+	assert.Equal(t, []interface{}{1, 3}, results, "should filter values")
+
+	results, _ = receiveAll(emitAll(1, 2).Concat(emitAll(3), emitAll(4, 5)))
+	assert.Equal(t, []interface{}{1, 2, 3, 4, 5}, results, "should concat observables in order")
+
+	results, _ = receiveAll(emitAll(1, 2).Merge(emitAll(3), emitAll(4, 5)))
+	assert.ElementsMatch(t, []interface{}{1, 2, 3, 4, 5}, results, "should merge the values of all observables")
+
+	_, err = receiveAll(emitAll(1).Merge(NewObservable(func(ctx context.Context, emit func(interface{}) bool) error {
+		return fmt.Errorf("some error")
+	})))
+	assert.EqualError(t, err, "some error", "should terminate a merged observable with the first error")
+
+	var attempts int32
+	results, err = receiveAll(NewObservable(func(ctx context.Context, emit func(interface{}) bool) error {
+		if atomic.AddInt32(&attempts, 1) < 3 {
+			return fmt.Errorf("some error")
+		}
+		emit("foobar")
+		return nil
+	}).Retry(2))
+	assert.NoError(t, err, "should retry an observable that terminates with an error")
+	assert.Equal(t, []interface{}{"foobar"}, results, "should emit the values of the successful attempt")
+	atomic.StoreInt32(&attempts, 0)
+	_, err = receiveAll(NewObservable(func(ctx context.Context, emit func(interface{}) bool) error {
+		atomic.AddInt32(&attempts, 1)
+		return fmt.Errorf("some error")
+	}).Retry(2))
+	assert.Error(t, err, "should terminate with the error once the retries are exhausted")
+	assert.Equal(t, int32(3), atomic.LoadInt32(&attempts), "should execute the observable once more for each retry")
+}
+
+func TestObservableTiming(t *testing.T) {
+	bursts := NewObservable(func(ctx context.Context, emit func(interface{}) bool) error {
+		for burst := 0; burst < 2; burst++ {
+			for i := 0; i < 3; i++ {
+				if !emit(burst*10 + i) {
+					return nil
+				}
+			}
+			time.Sleep(50 * time.Millisecond)
+		}
+		return nil
+	})
+
+	results, _ := receiveAll(bursts.Debounce(20 * time.Millisecond))
+	assert.Equal(t, []interface{}{2, 12}, results, "should only emit the last value of each burst")
+
+	results, _ = receiveAll(bursts.Throttle(20 * time.Millisecond))
+	assert.Equal(t, []interface{}{0, 10}, results, "should only emit the first value of each burst")
+
+	results, _ = receiveAll(bursts.Buffer(2, 0))
+	assert.Equal(t, []interface{}{[]interface{}{0, 1}, []interface{}{2, 10}, []interface{}{11, 12}}, results, "should buffer values by count")
+
+	results, _ = receiveAll(bursts.Buffer(0, 25*time.Millisecond))
+	assert.Equal(t, []interface{}{[]interface{}{0, 1, 2}, []interface{}{10, 11, 12}}, results, "should buffer values by time")
+
+	results, _ = receiveAll(emitAll("a", "b").SwitchMap(func(v interface{}) Observable {
+		return NewObservable(func(ctx context.Context, emit func(interface{}) bool) error {
+			for i := 0; i < 2; i++ {
+				select {
+				case <-time.After(10 * time.Millisecond):
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+				if !emit(fmt.Sprint(v, i)) {
+					return nil
+				}
+			}
+			return nil
+		})
+	}))
+	assert.Equal(t, []interface{}{"b0", "b1"}, results, "should cancel the previous inner observable once a new value arrives")
+}
+
+func TestObservableBackpressure(t *testing.T) {
+	ints := func(n int) Observable {
+		return NewObservable(func(ctx context.Context, emit func(interface{}) bool) error {
+			for i := 0; i < n; i++ {
+				if !emit(i) {
+					return nil
+				}
+			}
+			return nil
+		})
+	}
+	wait := func(o Observable) Observable {
+		return o.Concat(NewObservable(func(ctx context.Context, emit func(interface{}) bool) error {
+			time.Sleep(10 * time.Millisecond)
+			return nil
+		}))
+	}
+
+	subscription := wait(ints(5)).Subscribe(context.Background(), WithBackpressure(2, BackpressureDropNewest))
+	time.Sleep(20 * time.Millisecond)
+	var results []interface{}
+	for v := range subscription {
+		results = append(results, v.Data)
+	}
+	assert.Equal(t, []interface{}{0, 1}, results, "should drop the newest values once the buffer is full")
+
+	subscription = wait(ints(5)).Subscribe(context.Background(), WithBackpressure(2, BackpressureDropOldest))
+	time.Sleep(20 * time.Millisecond)
+	results = nil
+	for v := range subscription {
+		results = append(results, v.Data)
+	}
+	assert.Equal(t, []interface{}{3, 4}, results, "should drop the oldest values once the buffer is full")
+
+	subscription = ints(5).Subscribe(context.Background(), WithBackpressure(2, BackpressureFail))
+	time.Sleep(20 * time.Millisecond)
+	results = nil
+	var err error
+	for v := range subscription {
+		if v.Error != nil {
+			err = v.Error
+		} else {
+			results = append(results, v.Data)
+		}
+	}
+	assert.Equal(t, []interface{}{0, 1}, results, "should receive the buffered values")
+	assert.Equal(t, BackpressureError{2}, err, "should terminate with a BackpressureError once the buffer is full")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	subscription = NewObservable(func(ctx context.Context, emit func(interface{}) bool) error {
+		for i := 0; emit(i); i++ {
+		}
+		return nil
+	}).Subscribe(ctx)
+	<-subscription
+	cancel()
+	for range subscription {
+	}
+}
+
+func TestObservableShare(t *testing.T) {
+	var executions int32
+	ticks := make(chan int)
+	hot := NewObservable(func(ctx context.Context, emit func(interface{}) bool) error {
+		atomic.AddInt32(&executions, 1)
+		for {
+			select {
+			case i, ok := <-ticks:
+				if !ok {
+					return nil
+				}
+				if !emit(i) {
+					return nil
+				}
+			case <-ctx.Done():
+				return nil
+			}
+		}
+	}).Share()
+
+	first := hot.Subscribe(context.Background(), WithBackpressure(1, BackpressureBlock))
+	ticks <- 1
+	assert.Equal(t, 1, (<-first).Data, "should receive values emitted after subscribing")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	second := hot.Subscribe(ctx, WithBackpressure(1, BackpressureBlock))
+	time.Sleep(10 * time.Millisecond)
+	ticks <- 2
+	assert.Equal(t, 2, (<-first).Data, "should multicast values to every subscriber")
+	assert.Equal(t, 2, (<-second).Data, "should not receive values emitted before subscribing")
+	assert.Equal(t, int32(1), atomic.LoadInt32(&executions), "should execute a hot observable once for all subscribers")
+
+	cancel()
+	for range second {
+	}
+	ticks <- 3
+	assert.Equal(t, 3, (<-first).Data, "should keep emitting to the remaining subscribers")
+	close(ticks)
+	_, ok := <-first
+	assert.Equal(t, false, ok, "should complete every subscriber once the source completes")
+}
+
+func TestObservableBridges(t *testing.T) {
+	f := NewFuture(func() (interface{}, error) {
+		return "foobar", nil
+	})
+	o := FromFuture(f)
+	results, _ := receiveAll(o)
+	assert.Equal(t, []interface{}{"foobar"}, results, "should emit the resolved value of a Future")
+	results, _ = receiveAll(o)
+	assert.Equal(t, []interface{}{"foobar"}, results, "should share the resolved value of a Future with every subscriber")
+
+	_, err := receiveAll(FromFuture(NewFuture(func() (interface{}, error) {
+		return nil, fmt.Errorf("some error")
+	})))
+	assert.EqualError(t, err, "some error", "should terminate with the error of a Future")
+
+	results, _ = receiveAll(FromGenerator(count(1, 3)))
+	assert.Equal(t, []interface{}{1, 2, 3}, results, "should emit the values of a Generator")
+
+	value := <-FromGenerator(count(1, 5)).Map(func(v interface{}) (interface{}, error) {
+		return v.(int) * 2, nil
+	}).ToFuture()
+	assert.Equal(t, 10, value.Data, "should resolve a Future with the last value")
+
+	value = <-emitAll(1).Concat(NewObservable(func(ctx context.Context, emit func(interface{}) bool) error {
+		return fmt.Errorf("some error")
+	})).ToFuture()
+	assert.EqualError(t, value.Error, "some error", "should resolve a Future with the error of the observable")
+
+	<-FromGenerator(naturals()).Filter(func(v interface{}) bool {
+		return v.(int) > 3
+	}).Subscribe(context.Background(), WithBackpressure(1, BackpressureFail))
+	assert.Eventually(t, func() bool {
+		return runningGenerators() == 0
+	}, time.Second, 10*time.Millisecond, "should close a Generator once the subscriber stops receiving values")
+}
@@ -0,0 +1,144 @@
+package futures
+
+import (
+	"context"
+)
+
+// RejectionPolicy determines what Send does with a FutureFunc when the queue of a WorkerPool is full
+type RejectionPolicy int
+
+const (
+	// PolicyBlock blocks the caller of Send until there is room in the queue
+	PolicyBlock RejectionPolicy = iota
+	// PolicyReject resolves the FutureFunc with a RejectedTaskError without executing it
+	PolicyReject
+	// PolicyDropOldest resolves the oldest queued FutureFunc with a RejectedTaskError to make room for the new one. Blocks like PolicyBlock when the queue capacity is zero since nothing is ever queued.
+	PolicyDropOldest
+	// PolicyCallerRuns executes the FutureFunc in the go routine calling Send
+	PolicyCallerRuns
+)
+
+// RejectedTaskError implements the error interface and is returned for a FutureFunc that was rejected by the RejectionPolicy of a WorkerPool because its queue was full
+type RejectedTaskError struct{}
+
+// Error returns an error message for RejectedTaskError
+func (RejectedTaskError) Error() string {
+	return "task was rejected because the worker pool queue is full"
+}
+
+// WithQueueCapacity sets how many FutureFuncs can be queued before the RejectionPolicy of a WorkerPool applies. Defaults to the concurrency of the WorkerPool.
+// The queue is shared by a WorkerPool and its NestedWorkerPools so passing this option to Fork has no effect.
+func WithQueueCapacity(capacity int) WorkerPoolOption {
+	return func(c *workerPoolConfig) {
+		if capacity < 0 {
+			capacity = 0
+		}
+		c.queue = capacity
+	}
+}
+
+// WithRejectionPolicy sets what Send does with a FutureFunc when the queue of a WorkerPool is full. Defaults to PolicyBlock.
+// The policy is shared by a WorkerPool and its NestedWorkerPools so passing this option to Fork has no effect.
+func WithRejectionPolicy(policy RejectionPolicy) WorkerPoolOption {
+	return func(c *workerPoolConfig) {
+		c.rejection = policy
+	}
+}
+
+type submitMode int
+
+const (
+	submitPolicy submitMode = iota
+	submitTry
+	submitWait
+)
+
+// submit prepares a task with the defaults of the WorkerPool, waits for its RateLimiters in the calling go routine and queues it.
+// Tasks executed by PolicyCallerRuns are run after the sendLock is released so that they do not hold up Close.
+func (w WorkerPool) submit(t *task, mode submitMode, ctx context.Context) error {
+	if w.closed() {
+		return ClosedWorkerPoolError{}
+	}
+	t.middleware = append(append([]Middleware{}, w.state.middleware...), t.middleware...)
+	t.limiters = append(t.limiters, w.state.limiters...)
+	if t.timeout == 0 {
+		t.timeout = w.state.taskTimeout
+	}
+	t.metrics = append(t.metrics, w.state.metrics)
+	timedOut, err := t.throttle(mode, ctx)
+	if err != nil {
+		return err
+	}
+
+	w.sendLock.RLock()
+	if w.closed() {
+		w.sendLock.RUnlock()
+		return ClosedWorkerPoolError{}
+	}
+	for _, m := range t.metrics {
+		m.submit()
+	}
+	if timedOut {
+		w.sendLock.RUnlock()
+		go t.fail(TimeoutError{t.timeout})
+		return nil
+	}
+	w.grow()
+	callerRuns, err := w.enqueue(t, mode, ctx)
+	w.sendLock.RUnlock()
+	if callerRuns {
+		t.run(&workerPoolState{})
+	}
+	return err
+}
+
+// enqueue pushes a task onto the queue and applies the RejectionPolicy if the queue is full. Returns true if the task should be executed by the caller instead.
+func (w WorkerPool) enqueue(t *task, mode submitMode, ctx context.Context) (bool, error) {
+	if local := w.state.scheduler.local(ctx); local != nil {
+		local.push(t)
+		return false, nil
+	}
+	select {
+	case w.in <- t:
+		return false, nil
+	default:
+	}
+	switch {
+	case mode == submitTry:
+		t.withdraw()
+		return false, RejectedTaskError{}
+	case mode == submitWait || w.state.rejection == PolicyBlock || w.state.rejection == PolicyDropOldest && cap(w.in) == 0:
+		var done <-chan struct{}
+		if ctx != nil {
+			done = ctx.Done()
+		}
+		select {
+		case w.in <- t:
+			return false, nil
+		case <-w.kill:
+			t.withdraw()
+			return false, ClosedWorkerPoolError{}
+		case <-done:
+			t.withdraw()
+			return false, ctx.Err()
+		}
+	case w.state.rejection == PolicyReject:
+		go t.fail(RejectedTaskError{})
+		return false, nil
+	case w.state.rejection == PolicyDropOldest:
+		for {
+			select {
+			case w.in <- t:
+				return false, nil
+			default:
+			}
+			select {
+			case oldest := <-w.in:
+				go oldest.fail(RejectedTaskError{})
+			default:
+			}
+		}
+	default:
+		return true, nil
+	}
+}
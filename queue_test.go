@@ -0,0 +1,212 @@
+package futures
+
+import (
+	"context"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// occupy blocks the single worker of wp until the returned channel is closed and fills its queue of size one
+func occupy(t *testing.T, wp WorkerPoolInterface, out chan Value) chan struct{} {
+	gate := make(chan struct{})
+	wp.Do(out, func() (interface{}, error) {
+		<-gate
+		return "busy", nil
+	})
+	assert.Eventually(t, func() bool {
+		return wp.Stats().BusyWorkers == 1
+	}, time.Second, 10*time.Millisecond, "should start executing the blocking FutureFunc")
+	wp.Do(out, func() (interface{}, error) {
+		return "queued", nil
+	})
+	return gate
+}
+
+func TestWorkerPoolQueue(t *testing.T) {
+	wp := NewFuturesWorkerPool(1, WithQueueCapacity(1))
+	out := make(chan Value, 10)
+	gate := occupy(t, wp, out)
+
+	assert.Equal(t, false, wp.TrySend(func() (interface{}, error) {
+		return "foobar", nil
+	}), "should return false without blocking if the queue is full")
+	assert.Equal(t, uint64(2), wp.Stats().Submitted, "should not count a FutureFunc that was not queued")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := wp.SendWithContext(ctx, func() (interface{}, error) {
+		return "foobar", nil
+	})
+	assert.Equal(t, context.DeadlineExceeded, err, "should return the error of the context once it is done before the FutureFunc could be queued")
+
+	sent := make(chan error)
+	go func() {
+		sent <- wp.SendWithContext(context.Background(), func() (interface{}, error) {
+			return "foobar", nil
+		})
+	}()
+	close(gate)
+	assert.NoError(t, <-sent, "should queue the FutureFunc once there is room in the queue")
+	results := []interface{}{(<-out).Data, (<-out).Data}
+	value, _ := wp.Receive()
+	assert.Equal(t, []interface{}{"busy", "queued"}, results, "should execute the queued FutureFuncs")
+	assert.Equal(t, "foobar", value.Data, "should execute a FutureFunc sent with a context")
+
+	assert.Equal(t, true, wp.TrySend(func() (interface{}, error) {
+		return "foobar", nil
+	}), "should return true if the FutureFunc was queued")
+	wp.Receive()
+
+	gate = occupy(t, wp, out)
+	go func() {
+		sent <- wp.SendWithContext(context.Background(), func() (interface{}, error) {
+			return "foobar", nil
+		})
+	}()
+	time.Sleep(10 * time.Millisecond)
+	wp.Close()
+	assert.Equal(t, ClosedWorkerPoolError{}, <-sent, "should stop blocking once the WorkerPool is closed")
+	close(gate)
+	assert.Equal(t, false, wp.TrySend(func() (interface{}, error) {
+		return "foobar", nil
+	}), "should return false if the WorkerPool has been closed")
+
+	wp = NewFuturesWorkerPool(1, WithQueueCapacity(1))
+	np := wp.Fork(1)
+	gate = occupy(t, wp, out)
+	assert.Equal(t, false, np.TrySend(func() (interface{}, error) {
+		return "foobar", nil
+	}), "should share the queue of the parent WorkerPool with a NestedWorkerPool")
+	close(gate)
+	assert.NoError(t, np.SendWithContext(context.Background(), func() (interface{}, error) {
+		return "foobar", nil
+	}), "should queue the FutureFunc of a NestedWorkerPool once there is room in the queue")
+	value, _ = np.Receive()
+	assert.Equal(t, "foobar", value.Data, "should deliver the value to the NestedWorkerPool")
+	wp.Close()
+}
+
+func TestWorkerPoolRejectionPolicy(t *testing.T) {
+	wp := NewFuturesWorkerPool(1, WithQueueCapacity(1), WithRejectionPolicy(PolicyReject))
+	out := make(chan Value, 10)
+	gate := occupy(t, wp, out)
+	var executed int32
+	assert.Equal(t, true, wp.Do(out, func() (interface{}, error) {
+		atomic.AddInt32(&executed, 1)
+		return "rejected", nil
+	}), "should not block once the queue is full")
+	assert.Equal(t, RejectedTaskError{}, (<-out).Error, "should resolve a rejected FutureFunc with a RejectedTaskError")
+	close(gate)
+	assert.Equal(t, "busy", (<-out).Data)
+	assert.Equal(t, "queued", (<-out).Data)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&executed), "should not execute a rejected FutureFunc")
+	wp.Close()
+
+	wp = NewFuturesWorkerPool(1, WithQueueCapacity(1), WithRejectionPolicy(PolicyDropOldest))
+	gate = occupy(t, wp, out)
+	wp.Do(out, func() (interface{}, error) {
+		return "newest", nil
+	})
+	assert.Equal(t, RejectedTaskError{}, (<-out).Error, "should reject the oldest queued FutureFunc")
+	close(gate)
+	assert.Equal(t, "busy", (<-out).Data)
+	assert.Equal(t, "newest", (<-out).Data, "should execute the newest FutureFunc")
+	wp.Close()
+
+	wp = NewFuturesWorkerPool(1, WithQueueCapacity(0), WithRejectionPolicy(PolicyDropOldest))
+	gate = make(chan struct{})
+	wp.Do(out, func() (interface{}, error) {
+		<-gate
+		return "busy", nil
+	})
+	sent := make(chan bool)
+	go func() {
+		sent <- wp.Do(out, func() (interface{}, error) {
+			return "newest", nil
+		})
+	}()
+	select {
+	case <-sent:
+		t.Error("should block without a queue to drop FutureFuncs from")
+	case <-time.After(10 * time.Millisecond):
+	}
+	close(gate)
+	assert.Equal(t, true, <-sent, "should hand the FutureFunc to the worker once it is free")
+	assert.Equal(t, "busy", (<-out).Data)
+	assert.Equal(t, "newest", (<-out).Data)
+	wp.Close()
+
+	wp = NewFuturesWorkerPool(1, WithQueueCapacity(1), WithRejectionPolicy(PolicyCallerRuns))
+	gate = occupy(t, wp, out)
+	caller := make(chan struct{})
+	go func() {
+		defer close(caller)
+		wp.Do(out, func() (interface{}, error) {
+			return "caller", nil
+		})
+	}()
+	<-caller
+	assert.Equal(t, "caller", (<-out).Data, "should execute the FutureFunc in the calling go routine once the queue is full")
+	close(gate)
+	assert.Equal(t, "busy", (<-out).Data)
+	assert.Equal(t, "queued", (<-out).Data)
+	wp.Close()
+
+	wp = NewFuturesWorkerPool(1, WithQueueCapacity(1))
+	gate = occupy(t, wp, out)
+	sent = make(chan bool)
+	go func() {
+		sent <- wp.Do(out, func() (interface{}, error) {
+			return "blocked", nil
+		})
+	}()
+	select {
+	case <-sent:
+		t.Error("should block while the queue is full")
+	case <-time.After(10 * time.Millisecond):
+	}
+	close(gate)
+	assert.Equal(t, true, <-sent, "should queue the FutureFunc once there is room in the queue")
+	for i := 0; i < 3; i++ {
+		<-out
+	}
+	wp.Close()
+}
+
+func TestWorkerPoolQueueCombinators(t *testing.T) {
+	wp := NewFuturesWorkerPool(1, WithQueueCapacity(1))
+	defer wp.Close()
+	gate := make(chan struct{})
+	values := make([]interface{}, 100)
+	for i := range values {
+		values[i] = i
+	}
+	before := runtime.NumGoroutine()
+	f := MapWithWorkerPool(values, func(value interface{}) (interface{}, error) {
+		<-gate
+		return value, nil
+	}, 1, wp)
+	time.Sleep(10 * time.Millisecond)
+	assert.True(t, runtime.NumGoroutine()-before < 10, "should not start a go routine for each value that does not fit in the queue")
+	close(gate)
+	value := <-f
+	assert.NoError(t, value.Error)
+	assert.Len(t, value.Data, 100, "should execute every value once there is room in the queue")
+
+	rejecting := NewFuturesWorkerPool(1, WithQueueCapacity(1), WithRejectionPolicy(PolicyReject))
+	defer rejecting.Close()
+	gate = make(chan struct{})
+	f = MapWithWorkerPool(values, func(value interface{}) (interface{}, error) {
+		<-gate
+		return value, nil
+	}, 1, rejecting)
+	time.Sleep(10 * time.Millisecond)
+	close(gate)
+	value = <-f
+	assert.NoError(t, value.Error, "should hold back values while the queue is full regardless of the RejectionPolicy")
+	assert.Len(t, value.Data, 100)
+}
@@ -0,0 +1,187 @@
+package futures
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// RateLimiterOption configures optional behavior of a RateLimiter created with NewRateLimiter
+type RateLimiterOption func(*RateLimiter)
+
+// WithBurst allows up to burst executions to start at once before the rate applies. The default burst of 1 spaces executions evenly like a leaky bucket.
+func WithBurst(burst int) RateLimiterOption {
+	return func(rl *RateLimiter) {
+		rl.limit.burst = burst
+	}
+}
+
+// WithKeyLimit overrides the rate and burst of executions that wait with the provided key. Panics if requests or per is not positive.
+func WithKeyLimit(key interface{}, requests int, per time.Duration, burst int) RateLimiterOption {
+	rate := newRate(requests, per)
+	return func(rl *RateLimiter) {
+		rl.keys[key] = rateLimit{rate, burst}
+	}
+}
+
+// rateLimiterSweep is how many buckets a RateLimiter holds before it first removes the buckets of idle keys
+const rateLimiterSweep = 64
+
+// RateLimiter is a token bucket that limits how many executions start per period of time. Each key has its own bucket.
+type RateLimiter struct {
+	lock    sync.Mutex
+	limit   rateLimit
+	keys    map[interface{}]rateLimit
+	buckets map[interface{}]*tokenBucket
+	sweepAt int
+}
+
+type rateLimit struct {
+	rate  float64
+	burst int
+}
+
+type tokenBucket struct {
+	rateLimit
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter returns a RateLimiter that allows the provided number of requests per period of time for each key. Panics if requests or per is not positive.
+func NewRateLimiter(requests int, per time.Duration, opts ...RateLimiterOption) *RateLimiter {
+	rl := &RateLimiter{
+		limit:   rateLimit{newRate(requests, per), 1},
+		keys:    map[interface{}]rateLimit{},
+		buckets: map[interface{}]*tokenBucket{},
+		sweepAt: rateLimiterSweep,
+	}
+	for _, opt := range opts {
+		opt(rl)
+	}
+	return rl
+}
+
+// newRate returns the number of tokens per second added to a bucket that allows requests per period of time
+func newRate(requests int, per time.Duration) float64 {
+	if requests <= 0 || per <= 0 {
+		panic(fmt.Sprintf("futures: rate limit of %d requests per %s must be positive", requests, per))
+	}
+	return float64(requests) / per.Seconds()
+}
+
+// Wait blocks until the bucket of key has a token and returns the error of the context if it is done first
+func (rl *RateLimiter) Wait(ctx context.Context, key interface{}) error {
+	delay := rl.reserve(key, time.Now())
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		rl.restore(key)
+		return ctx.Err()
+	}
+}
+
+// reserve takes a token from the bucket of key and returns how long to wait until the token is available
+func (rl *RateLimiter) reserve(key interface{}, now time.Time) time.Duration {
+	rl.lock.Lock()
+	defer rl.lock.Unlock()
+	bucket := rl.bucket(key, now)
+	bucket.tokens--
+	if bucket.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-bucket.tokens / bucket.rate * float64(time.Second))
+}
+
+// take takes a token from the bucket of key only if one is available right away
+func (rl *RateLimiter) take(key interface{}) bool {
+	rl.lock.Lock()
+	defer rl.lock.Unlock()
+	bucket := rl.bucket(key, time.Now())
+	if bucket.tokens < 1 {
+		return false
+	}
+	bucket.tokens--
+	return true
+}
+
+// restore returns a token that was taken but not used to the bucket of key
+func (rl *RateLimiter) restore(key interface{}) {
+	rl.lock.Lock()
+	defer rl.lock.Unlock()
+	bucket := rl.bucket(key, time.Now())
+	if bucket.tokens < float64(bucket.burst) {
+		bucket.tokens++
+	}
+}
+
+// bucket returns the bucket of key refilled up to now. Must be called with the lock held.
+func (rl *RateLimiter) bucket(key interface{}, now time.Time) *tokenBucket {
+	bucket, ok := rl.buckets[key]
+	if !ok {
+		if len(rl.buckets) >= rl.sweepAt {
+			rl.sweep(now)
+		}
+		limit, ok := rl.keys[key]
+		if !ok {
+			limit = rl.limit
+		}
+		if limit.burst < 1 {
+			limit.burst = 1
+		}
+		bucket = &tokenBucket{limit, float64(limit.burst), now}
+		rl.buckets[key] = bucket
+	}
+	bucket.refill(now)
+	return bucket
+}
+
+// sweep removes the buckets that have refilled completely since a full bucket is the same as the new bucket created for a key the next time it waits.
+// The next sweep happens once the number of buckets has doubled so the cost of sweeping is spread over the keys that were added. Must be called with the lock held.
+func (rl *RateLimiter) sweep(now time.Time) {
+	for key, bucket := range rl.buckets {
+		if bucket.refill(now) {
+			delete(rl.buckets, key)
+		}
+	}
+	rl.sweepAt = 2 * len(rl.buckets)
+	if rl.sweepAt < rateLimiterSweep {
+		rl.sweepAt = rateLimiterSweep
+	}
+}
+
+// refill adds the tokens of the time passed since the bucket was last refilled and returns true if the bucket is full
+func (bucket *tokenBucket) refill(now time.Time) bool {
+	if now.After(bucket.last) {
+		bucket.tokens += now.Sub(bucket.last).Seconds() * bucket.rate
+		bucket.last = now
+	}
+	if bucket.tokens >= float64(bucket.burst) {
+		bucket.tokens = float64(bucket.burst)
+		return true
+	}
+	return false
+}
+
+// WithRateLimiter makes every FutureFunc sent to a WorkerPool or NestedWorkerPool wait for a token from the RateLimiter before it is queued so that waiting does not occupy a worker.
+// NestedWorkerPools inherit the RateLimiters of their parent and FutureFuncs wait on each of them using the bucket of the key set with RateLimitKey.
+// Send blocks the caller while it waits, SendWithContext stops waiting once its context is done and TrySend returns false if there is no token. A FutureFunc that does not
+// receive a token within its TaskTimeout resolves with a TimeoutError without being executed.
+func WithRateLimiter(limiter *RateLimiter) WorkerPoolOption {
+	return func(c *workerPoolConfig) {
+		c.limiters = append(c.limiters, limiter)
+	}
+}
+
+// RateLimitKey sets the key of the RateLimiter bucket that a single FutureFunc waits on
+func RateLimitKey(key interface{}) TaskOption {
+	return func(t *task) {
+		t.rateLimitKey = key
+	}
+}
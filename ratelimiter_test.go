@@ -0,0 +1,101 @@
+package futures
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiter(t *testing.T) {
+	limiter := NewRateLimiter(20, time.Second, WithBurst(2), WithKeyLimit("slow", 1, time.Hour, 1))
+	wp := NewFuturesWorkerPool(4, WithRateLimiter(limiter))
+	defer wp.Close()
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		wp.Send(func() (interface{}, error) {
+			return time.Since(start), nil
+		})
+	}
+	var latest time.Duration
+	for i := 0; i < 4; i++ {
+		value, _ := wp.Receive()
+		if elapsed := value.Data.(time.Duration); elapsed > latest {
+			latest = elapsed
+		}
+	}
+	assert.True(t, latest >= 90*time.Millisecond, "should wait for a token once the burst is used up")
+	assert.True(t, latest < time.Second, "should allow executions at the configured rate")
+
+	run := func() (interface{}, error) {
+		return "foobar", nil
+	}
+	wp.Send(run, RateLimitKey("slow"))
+	value, _ := wp.Receive()
+	assert.Equal(t, "foobar", value.Data, "should allow the burst of a key")
+	wp.Send(run, RateLimitKey("slow"), TaskTimeout(20*time.Millisecond))
+	value, _ = wp.Receive()
+	assert.Equal(t, TimeoutError{20 * time.Millisecond}, value.Error, "should stop waiting for a token once the TaskTimeout has passed")
+
+	np := wp.Fork(1, WithRateLimiter(NewRateLimiter(1, time.Hour)))
+	np.Send(run)
+	value, _ = np.Receive()
+	assert.Equal(t, "foobar", value.Data, "should apply the RateLimiter of a NestedWorkerPool")
+	np.Send(run, TaskTimeout(10*time.Millisecond))
+	value, _ = np.Receive()
+	assert.IsType(t, TimeoutError{}, value.Error, "should apply the RateLimiter of a NestedWorkerPool")
+
+	single := NewFuturesWorkerPool(1, WithRateLimiter(NewRateLimiter(1, time.Hour)))
+	defer single.Close()
+	single.Send(run, RateLimitKey("slow"))
+	single.Receive()
+	assert.Equal(t, false, single.TrySend(run, RateLimitKey("slow")), "should return false from TrySend if there is no token")
+	ctx, cancel := context.WithCancel(context.Background())
+	waiting := make(chan error)
+	go func() {
+		waiting <- single.SendWithContext(ctx, run, RateLimitKey("slow"))
+	}()
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, 0, single.Stats().BusyWorkers, "should not occupy a worker while waiting for a token")
+	assert.Equal(t, true, single.TrySend(run, RateLimitKey("fast")), "should execute FutureFuncs of other keys while a key is throttled")
+	value, _ = single.Receive()
+	assert.Equal(t, "foobar", value.Data)
+	cancel()
+	assert.Equal(t, context.Canceled, <-waiting, "should stop waiting for a token once the context of SendWithContext is done")
+	assert.Equal(t, uint64(2), single.Stats().Submitted, "should not queue a FutureFunc that stopped waiting for a token")
+
+	ctx, cancel = context.WithCancel(context.Background())
+	limiter = NewRateLimiter(1, time.Hour)
+	assert.NoError(t, limiter.Wait(ctx, nil), "should not wait while there are tokens")
+	cancel()
+	assert.Equal(t, context.Canceled, limiter.Wait(ctx, nil), "should return the error of the context when it is done while waiting")
+
+	start = time.Now()
+	<-Map([]interface{}{1, 2, 3}, func(v interface{}) (interface{}, error) {
+		return v, nil
+	}, 3, WithRateLimiter(NewRateLimiter(50, time.Second)))
+	assert.True(t, time.Since(start) >= 35*time.Millisecond, "should rate limit Map")
+
+	assert.Panics(t, func() { NewRateLimiter(0, time.Second) }, "should not allow a rate of zero requests")
+	assert.Panics(t, func() { NewRateLimiter(1, 0) }, "should not allow a rate over a period of zero")
+	assert.Panics(t, func() { WithKeyLimit("key", 0, time.Second, 1) }, "should not allow a key rate of zero requests")
+
+	limiter = NewRateLimiter(1000, time.Millisecond)
+	for i := 0; i < rateLimiterSweep; i++ {
+		assert.Equal(t, true, limiter.take(i))
+	}
+	time.Sleep(5 * time.Millisecond)
+	limiter.take("next")
+	assert.Equal(t, 1, len(limiter.buckets), "should remove the buckets of idle keys")
+
+	first, second := NewRateLimiter(1, time.Hour), NewRateLimiter(1, time.Hour)
+	assert.Equal(t, true, second.take("key"))
+	rolled := NewFuturesWorkerPool(1, WithRateLimiter(first), WithRateLimiter(second))
+	defer rolled.Close()
+	rolled.Send(run, RateLimitKey("key"), TaskTimeout(10*time.Millisecond))
+	value, _ = rolled.Receive()
+	assert.IsType(t, TimeoutError{}, value.Error, "should time out waiting for the second RateLimiter")
+	assert.Equal(t, true, first.take("key"), "should return the token taken from the first RateLimiter when the second one times out")
+}
@@ -0,0 +1,108 @@
+package futures
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CompensationFunc specifies the function signature expected for undoing a step of a Saga. It receives the result of the step it compensates.
+type CompensationFunc func(interface{}) error
+
+// SagaError implements the error interface and is returned when a step of a Saga fails. Compensated lists the steps that were compensated in the order in which their CompensationFuncs ran and
+// CompensationErrors contains the error of each CompensationFunc that failed.
+type SagaError struct {
+	Step               string
+	Err                error
+	Compensated        []string
+	CompensationErrors map[string]error
+}
+
+// Error returns an error message for SagaError
+func (e SagaError) Error() string {
+	msg := fmt.Sprintf("saga step %q failed: %s", e.Step, e.Err)
+	if len(e.Compensated) > 0 {
+		msg += fmt.Sprintf("; compensated %s", strings.Join(e.Compensated, ", "))
+	}
+	for _, name := range e.Compensated {
+		if err, ok := e.CompensationErrors[name]; ok {
+			msg += fmt.Sprintf("; compensation of %q failed: %s", name, err)
+		}
+	}
+	return msg
+}
+
+// Unwrap returns the error of the failed step
+func (e SagaError) Unwrap() error {
+	return e.Err
+}
+
+type sagaStep struct {
+	name       string
+	action     ThenableFunc
+	compensate CompensationFunc
+}
+
+// sagaStepError marks the step of a Saga that failed as the error is passed along the chain of Thens to the Catch running the compensations
+type sagaStepError struct {
+	step int
+	err  error
+}
+
+func (e sagaStepError) Error() string {
+	return e.err.Error()
+}
+
+// Saga executes a series of steps that each have a CompensationFunc that undoes the step if a later step fails
+type Saga struct {
+	steps []sagaStep
+}
+
+// NewSaga creates a Saga without any steps
+func NewSaga() *Saga {
+	return &Saga{}
+}
+
+// Step adds a named step that executes action with the result of the previous step. When a later step fails compensate is called with the result of action. A nil compensate means the step does not need to be undone.
+func (s *Saga) Step(name string, action ThenableFunc, compensate CompensationFunc) *Saga {
+	s.steps = append(s.steps, sagaStep{name, action, compensate})
+	return s
+}
+
+// Run executes the steps of the Saga in order starting with argv and returns a Future that resolves with the result of the last step.
+// When a step fails the CompensationFuncs of the completed steps are called in reverse order, continuing past any that fail, and the Future resolves with a SagaError.
+func (s *Saga) Run(argv interface{}) Future {
+	steps := append([]sagaStep{}, s.steps...)
+	results := make([]interface{}, 0, len(steps))
+	f := NewFuture(func() (interface{}, error) {
+		return argv, nil
+	})
+	for i, step := range steps {
+		index, action := i, step.action
+		f = f.Then(func(v interface{}) (interface{}, error) {
+			result, err := action(v)
+			if err != nil {
+				return nil, sagaStepError{index, err}
+			}
+			results = append(results, result)
+			return result, nil
+		})
+	}
+	return f.Catch(func(err error) (interface{}, error) {
+		failed, ok := err.(sagaStepError)
+		if !ok {
+			return nil, err
+		}
+		sagaErr := SagaError{Step: steps[failed.step].name, Err: failed.err, CompensationErrors: map[string]error{}}
+		for i := len(results) - 1; i >= 0; i-- {
+			step := steps[i]
+			if step.compensate == nil {
+				continue
+			}
+			sagaErr.Compensated = append(sagaErr.Compensated, step.name)
+			if err := step.compensate(results[i]); err != nil {
+				sagaErr.CompensationErrors[step.name] = err
+			}
+		}
+		return nil, sagaErr
+	})
+}
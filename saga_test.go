@@ -0,0 +1,55 @@
+package futures
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSaga(t *testing.T) {
+	var log []string
+	step := func(name string) ThenableFunc {
+		return func(v interface{}) (interface{}, error) {
+			log = append(log, name)
+			return fmt.Sprintf("%v>%s", v, name), nil
+		}
+	}
+	compensate := func(name string) CompensationFunc {
+		return func(v interface{}) error {
+			log = append(log, "undo "+fmt.Sprint(v))
+			return nil
+		}
+	}
+
+	value := <-NewSaga().
+		Step("reserve", step("reserve"), compensate("reserve")).
+		Step("charge", step("charge"), compensate("charge")).
+		Step("ship", step("ship"), nil).
+		Run("order")
+	assert.NoError(t, value.Error)
+	assert.Equal(t, "order>reserve>charge>ship", value.Data, "should resolve with the result of the last step")
+	assert.Equal(t, []string{"reserve", "charge", "ship"}, log, "should not compensate a Saga that succeeded")
+
+	log = nil
+	value = <-NewSaga().
+		Step("reserve", step("reserve"), compensate("reserve")).
+		Step("notify", step("notify"), nil).
+		Step("charge", step("charge"), func(v interface{}) error {
+			log = append(log, "undo "+fmt.Sprint(v))
+			return fmt.Errorf("refund failed")
+		}).
+		Step("ship", func(interface{}) (interface{}, error) {
+			return nil, fmt.Errorf("out of stock")
+		}, compensate("ship")).
+		Step("email", step("email"), compensate("email")).
+		Run("order")
+	assert.Equal(t, []string{"reserve", "notify", "charge", "undo order>reserve>notify>charge", "undo order>reserve"}, log, "should run the compensations of the completed steps in reverse order")
+	assert.Equal(t, SagaError{
+		Step:               "ship",
+		Err:                fmt.Errorf("out of stock"),
+		Compensated:        []string{"charge", "reserve"},
+		CompensationErrors: map[string]error{"charge": fmt.Errorf("refund failed")},
+	}, value.Error, "should report the failed step and which compensations ran and failed")
+	assert.EqualError(t, value.Error, `saga step "ship" failed: out of stock; compensated charge, reserve; compensation of "charge" failed: refund failed`)
+}
@@ -0,0 +1,44 @@
+//go:build go1.23
+
+package futures
+
+import (
+	"iter"
+)
+
+// Seq2 returns an iterator over the values and errors yielded by the Generator. The Generator is closed when the loop exits before the Generator is done.
+func (gen Generator) Seq2() iter.Seq2[interface{}, error] {
+	return func(yield func(interface{}, error) bool) {
+		next := pull(gen)
+		for {
+			v, ok := next()
+			if !ok {
+				return
+			}
+			if !yield(v.Value, v.Error) {
+				gen.Close()
+				return
+			}
+		}
+	}
+}
+
+// Seq returns an iterator over the values yielded by the Generator which stops at the first value with an error. Use Seq2 to receive errors. The Generator is closed when the loop exits before the Generator is done.
+func (gen Generator) Seq() iter.Seq[interface{}] {
+	return func(yield func(interface{}) bool) {
+		for value, err := range gen.Seq2() {
+			if err != nil || !yield(value) {
+				return
+			}
+		}
+	}
+}
+
+// FromSeq returns a Generator that yields each value of the provided iterator. Closing the Generator before it is done stops the iterator.
+func FromSeq(seq iter.Seq[interface{}]) Generator {
+	next, stop := iter.Pull(seq)
+	return derive(func() (GeneratorValue, bool) {
+		value, ok := next()
+		return GeneratorValue{Value: value}, ok
+	}, stop)
+}
@@ -0,0 +1,62 @@
+//go:build go1.23
+
+package futures
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGeneratorSeq(t *testing.T) {
+	var results []interface{}
+	for value, err := range count(1, 3).Seq2() {
+		assert.NoError(t, err, "should not yield an error")
+		results = append(results, value)
+	}
+	assert.Equal(t, []interface{}{1, 2, 3}, results, "should iterate over each value of the generator")
+
+	results = nil
+	for value := range naturals().Seq() {
+		if value.(int) > 2 {
+			break
+		}
+		results = append(results, value)
+	}
+	assert.Equal(t, []interface{}{1, 2}, results, "should stop iterating when the loop exits")
+
+	var errs []error
+	for _, err := range MapGen(count(1, 2), func(v interface{}) (interface{}, error) {
+		return nil, fmt.Errorf("failed %v", v)
+	}).Seq2() {
+		errs = append(errs, err)
+	}
+	assert.Equal(t, []error{fmt.Errorf("failed 1"), fmt.Errorf("failed 2")}, errs, "should yield the errors of the generator")
+
+	letters := FromSeq(func(yield func(interface{}) bool) {
+		for _, letter := range []string{"a", "b", "c"} {
+			if !yield(letter) {
+				return
+			}
+		}
+	})
+	assert.Equal(t, []interface{}{"a", "b", "c"}, collect(letters), "should yield each value of the iterator")
+
+	var stopped int32
+	infinite := FromSeq(func(yield func(interface{}) bool) {
+		defer atomic.StoreInt32(&stopped, 1)
+		for i := 0; yield(i); i++ {
+		}
+	})
+	infinite.Next()
+	infinite.Close()
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt32(&stopped) == 1
+	}, time.Second, 10*time.Millisecond, "should stop the iterator when the generator is closed")
+	assert.Eventually(t, func() bool {
+		return runningGenerators() == 0
+	}, time.Second, 10*time.Millisecond, "should not leak go routines when loops exit early")
+}
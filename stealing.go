@@ -0,0 +1,243 @@
+package futures
+
+import (
+	"context"
+	"sync"
+)
+
+// WithWorkStealing gives each worker go routine of a WorkerPool its own queue for the FutureFuncs it spawns. Idle workers steal FutureFuncs from the queues of busy workers and a worker that
+// awaits a spawned Future executes queued FutureFuncs in the meantime so that recursive fork/join FutureFuncs complete on a fixed number of workers. A worker that awaits the Future of a combinator
+// such as AllWithWorkerPool executes the queued FutureFuncs of the combinator the same way. Only the awaiting worker executes FutureFuncs while it waits so the WorkerPool never executes more
+// FutureFuncs at once than it has workers. FutureFuncs left in the local queues once the WorkerPool is closed resolve with a ClosedWorkerPoolError. Only applies to NewFuturesWorkerPool.
+func WithWorkStealing() WorkerPoolOption {
+	return func(c *workerPoolConfig) {
+		c.stealing = true
+	}
+}
+
+type workerKey struct{}
+
+// deque is the local queue of a worker go routine. The owning worker pops the most recently pushed task while other workers steal the oldest one.
+type deque struct {
+	lock      sync.Mutex
+	tasks     []*task
+	owned     bool
+	scheduler *scheduler
+}
+
+func (d *deque) push(t *task) {
+	d.lock.Lock()
+	d.tasks = append(d.tasks, t)
+	d.lock.Unlock()
+	d.scheduler.signal()
+}
+
+func (d *deque) pop() *task {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if len(d.tasks) == 0 {
+		return nil
+	}
+	t := d.tasks[len(d.tasks)-1]
+	d.tasks = d.tasks[:len(d.tasks)-1]
+	return t
+}
+
+func (d *deque) steal() *task {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if len(d.tasks) == 0 {
+		return nil
+	}
+	t := d.tasks[0]
+	d.tasks = d.tasks[1:]
+	return t
+}
+
+// take returns the next task from the local queue of the worker or steals one from another worker. Returns nil if every queue is empty.
+func (d *deque) take() *task {
+	if d == nil {
+		return nil
+	}
+	if t := d.pop(); t != nil {
+		return t
+	}
+	for _, victim := range d.scheduler.victims() {
+		if victim == d {
+			continue
+		}
+		if t := victim.steal(); t != nil {
+			return t
+		}
+	}
+	return nil
+}
+
+// scheduler tracks the local queues of the worker go routines of a WorkerPool. Queues are kept once their worker exits so that the remaining tasks can still be stolen and are handed to the next worker that starts.
+type scheduler struct {
+	lock   sync.Mutex
+	deques []*deque
+	wake   chan struct{}
+}
+
+func newScheduler(size int) *scheduler {
+	if size < 1 {
+		size = 1
+	}
+	return &scheduler{wake: make(chan struct{}, size)}
+}
+
+// drain empties every local queue once the WorkerPool is closed and returns the tasks that were never executed
+func (s *scheduler) drain() []*task {
+	if s == nil {
+		return nil
+	}
+	var tasks []*task
+	for _, d := range s.victims() {
+		for t := d.steal(); t != nil; t = d.steal() {
+			tasks = append(tasks, t)
+		}
+	}
+	return tasks
+}
+
+// acquire returns a local queue for a worker go routine that is starting
+func (s *scheduler) acquire() *deque {
+	if s == nil {
+		return nil
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for _, d := range s.deques {
+		if !d.owned {
+			d.owned = true
+			return d
+		}
+	}
+	d := &deque{owned: true, scheduler: s}
+	s.deques = append(s.deques, d)
+	return d
+}
+
+// release hands the local queue of a worker go routine that is exiting back to the scheduler and wakes another worker to steal its remaining tasks
+func (s *scheduler) release(d *deque) {
+	if s == nil {
+		return
+	}
+	s.lock.Lock()
+	d.owned = false
+	s.lock.Unlock()
+	s.signal()
+}
+
+func (s *scheduler) victims() []*deque {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return append([]*deque{}, s.deques...)
+}
+
+// signal wakes an idle worker go routine so that it steals a task
+func (s *scheduler) signal() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *scheduler) wakeup() chan struct{} {
+	if s == nil {
+		return nil
+	}
+	return s.wake
+}
+
+// local returns the queue of the worker go routine of this scheduler that is executing the FutureFunc the context was passed to
+func (s *scheduler) local(ctx context.Context) *deque {
+	if s == nil || ctx == nil {
+		return nil
+	}
+	d, ok := ctx.Value(workerKey{}).(*deque)
+	if !ok || d.scheduler != s {
+		return nil
+	}
+	return d
+}
+
+// Spawn queues a ContextFutureFunc and returns a Future that resolves with its value. When ctx is the context passed to a ContextFutureFunc executed by a WorkerPool with WithWorkStealing
+// the ContextFutureFunc is pushed to the local queue of the executing worker, otherwise Spawn blocks like SendWithContext until it is queued. The Future resolves with the error of the context or a
+// ClosedWorkerPoolError if the ContextFutureFunc could not be queued.
+func (w WorkerPool) Spawn(ctx context.Context, fn ContextFutureFunc, opts ...TaskOption) Future {
+	return spawn(w.submit, ctx, fn, opts)
+}
+
+// Spawn queues a ContextFutureFunc with the parent WorkerPool and returns a Future that resolves with its value
+func (n NestedWorkerPool) Spawn(ctx context.Context, fn ContextFutureFunc, opts ...TaskOption) Future {
+	return spawn(n.submit, ctx, fn, opts)
+}
+
+func spawn(submit func(*task, submitMode, context.Context) error, ctx context.Context, fn ContextFutureFunc, opts []TaskOption) Future {
+	out := make(chan Value, 1)
+	if err := submit(newTask(fn, func(v Value) {
+		out <- v
+	}, opts), submitWait, ctx); err != nil {
+		out <- Value{Error: err}
+	}
+	return out
+}
+
+// Await blocks until f resolves and returns its Value. When ctx is the context passed to a ContextFutureFunc executed by a WorkerPool with WithWorkStealing the worker executes queued
+// FutureFuncs while it waits instead of blocking. Returns the error of the context if it is done first.
+func (w WorkerPool) Await(ctx context.Context, f Future) Value {
+	if d := w.state.scheduler.local(ctx); d != nil {
+		return d.await(ctx, f, w.in)
+	}
+	var done <-chan struct{}
+	if ctx != nil {
+		done = ctx.Done()
+	}
+	select {
+	case v := <-f:
+		return v
+	case <-done:
+		return Value{Error: ctx.Err()}
+	}
+}
+
+// await executes tasks from the local queue d, the queues of other workers and the queue of the WorkerPool in the awaiting worker go routine until f delivers a Value.
+// Returns the error of the context if it is done first.
+func (d *deque) await(ctx context.Context, f Future, in chan *task) Value {
+	var done <-chan struct{}
+	if ctx != nil {
+		done = ctx.Done()
+	}
+	for {
+		select {
+		case v := <-f:
+			return v
+		default:
+		}
+		if t := d.take(); t != nil {
+			d.run(t)
+			continue
+		}
+		select {
+		case v := <-f:
+			return v
+		case <-done:
+			return Value{Error: ctx.Err()}
+		case t, ok := <-in:
+			if !ok {
+				in = nil
+				continue
+			}
+			d.run(t)
+		case <-d.scheduler.wake:
+		}
+	}
+}
+
+// run executes a task in the go routine of a worker that is awaiting a Future. The task is executed without a workerPoolState so that a timeout does not replace the awaiting worker.
+func (d *deque) run(t *task) {
+	t.worker = d
+	t.run(&workerPoolState{})
+}
@@ -0,0 +1,153 @@
+package futures
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkStealing(t *testing.T) {
+	wp := NewFuturesWorkerPool(2, WithWorkStealing())
+	defer wp.Close()
+
+	var sum func(wp WorkerPoolInterface, from, to int) ContextFutureFunc
+	sum = func(wp WorkerPoolInterface, from, to int) ContextFutureFunc {
+		return func(ctx context.Context) (interface{}, error) {
+			if to-from < 4 {
+				total := 0
+				for i := from; i <= to; i++ {
+					total += i
+				}
+				return total, nil
+			}
+			mid := (from + to) / 2
+			left := wp.Spawn(ctx, sum(wp, from, mid))
+			right := wp.Spawn(ctx, sum(wp, mid+1, to))
+			l, r := wp.Await(ctx, left), wp.Await(ctx, right)
+			return l.Data.(int) + r.Data.(int), nil
+		}
+	}
+	value := <-wp.Spawn(context.Background(), sum(wp, 1, 1000))
+	assert.NoError(t, value.Error)
+	assert.Equal(t, 500500, value.Data, "should complete recursive fork/join FutureFuncs with a fixed number of workers")
+
+	single := NewFuturesWorkerPool(1, WithWorkStealing())
+	value = <-single.Spawn(context.Background(), sum(single, 1, 100))
+	assert.Equal(t, 5050, value.Data, "should execute queued FutureFuncs while awaiting with a single worker")
+	single.Close()
+
+	var lock sync.Mutex
+	workers := map[*deque]bool{}
+	value = <-wp.Spawn(context.Background(), func(ctx context.Context) (interface{}, error) {
+		var children []Future
+		for i := 0; i < 8; i++ {
+			children = append(children, wp.Spawn(ctx, func(ctx context.Context) (interface{}, error) {
+				lock.Lock()
+				workers[ctx.Value(workerKey{}).(*deque)] = true
+				lock.Unlock()
+				time.Sleep(5 * time.Millisecond)
+				return nil, nil
+			}))
+		}
+		for _, child := range children {
+			wp.Await(ctx, child)
+		}
+		return nil, nil
+	})
+	assert.NoError(t, value.Error)
+	assert.Equal(t, 2, len(workers), "should steal FutureFuncs from the local queue of a busy worker")
+
+	np := wp.Fork(2)
+	value = <-np.Spawn(context.Background(), func(ctx context.Context) (interface{}, error) {
+		return np.Await(ctx, np.Spawn(ctx, func(ctx context.Context) (interface{}, error) {
+			return "foobar", nil
+		})).Data, nil
+	})
+	assert.Equal(t, "foobar", value.Data, "should spawn FutureFuncs with a NestedWorkerPool")
+	np.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	value = wp.Await(ctx, make(Future))
+	assert.Equal(t, context.Canceled, value.Error, "should stop awaiting once the context is done")
+
+	single = NewFuturesWorkerPool(1, WithWorkStealing())
+	value = <-single.Spawn(context.Background(), func(ctx context.Context) (interface{}, error) {
+		v := single.Await(ctx, AllWithWorkerPool([]interface{}{1, func() (interface{}, error) {
+			return 2, nil
+		}}, 2, single))
+		if v.Error != nil {
+			return nil, v.Error
+		}
+		w := single.Await(ctx, MapWithWorkerPool(v.Data.([]interface{}), func(value interface{}) (interface{}, error) {
+			return value.(int) * 10, nil
+		}, 2, single))
+		return w.Data, w.Error
+	})
+	assert.NoError(t, value.Error)
+	assert.ElementsMatch(t, []interface{}{10, 20}, value.Data, "should execute the FutureFuncs of combinators awaited by a worker while it waits on them")
+
+	var running, most int32
+	busy := func() (interface{}, error) {
+		if n := atomic.AddInt32(&running, 1); n > atomic.LoadInt32(&most) {
+			atomic.StoreInt32(&most, n)
+		}
+		time.Sleep(time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		return nil, nil
+	}
+	value = <-single.Spawn(context.Background(), func(ctx context.Context) (interface{}, error) {
+		fns := []interface{}{}
+		for i := 0; i < 8; i++ {
+			fns = append(fns, busy)
+		}
+		v := single.Await(ctx, AllWithWorkerPool(fns, 4, single))
+		return v.Data, v.Error
+	})
+	assert.NoError(t, value.Error)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&most), "should not execute more FutureFuncs at once than the WorkerPool has workers")
+
+	closed := make(chan Future, 1)
+	single.Spawn(context.Background(), func(ctx context.Context) (interface{}, error) {
+		child := single.Spawn(ctx, func(ctx context.Context) (interface{}, error) {
+			return "foobar", nil
+		})
+		single.Close()
+		closed <- child
+		return nil, nil
+	})
+	select {
+	case value = <-<-closed:
+		assert.Equal(t, ClosedWorkerPoolError{}, value.Error, "should reject FutureFuncs left in local queues once the WorkerPool is closed")
+	case <-time.After(time.Second):
+		t.Error("should resolve FutureFuncs left in local queues once the WorkerPool is closed")
+	}
+
+	stealing := NewFuturesWorkerPool(1, WithWorkStealing())
+	stealing.Spawn(context.Background(), func(ctx context.Context) (interface{}, error) {
+		for i := 0; i < 4; i++ {
+			assert.NoError(t, stealing.SendWithContext(ctx, func() (interface{}, error) {
+				return "foobar", nil
+			}), "should push FutureFuncs sent by a worker to its local queue")
+		}
+		stealing.Close()
+		return nil, nil
+	})
+	_, ok := stealing.Receive()
+	assert.Equal(t, false, ok, "should drop the Values of FutureFuncs left in local queues instead of sending them once the WorkerPool is closed")
+
+	plain := NewFuturesWorkerPool(1)
+	value = <-plain.Spawn(context.Background(), func(ctx context.Context) (interface{}, error) {
+		return "foobar", nil
+	})
+	assert.Equal(t, "foobar", value.Data, "should spawn FutureFuncs without work stealing")
+	plain.Close()
+	value = <-plain.Spawn(context.Background(), func(ctx context.Context) (interface{}, error) {
+		return "foobar", nil
+	})
+	assert.Equal(t, ClosedWorkerPoolError{}, value.Error, "should resolve with a ClosedWorkerPoolError once the WorkerPool is closed")
+}
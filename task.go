@@ -0,0 +1,188 @@
+package futures
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// TimeoutError implements the error interface and is returned for a FutureFunc executed by a WorkerPool that did not complete within its TaskTimeout
+type TimeoutError struct {
+	Timeout time.Duration
+}
+
+// Error returns an error message for TimeoutError
+func (e TimeoutError) Error() string {
+	return fmt.Sprintf("future execution timed out after %s", e.Timeout)
+}
+
+// TaskOption configures the execution of a single FutureFunc sent to a WorkerPool
+type TaskOption func(*task)
+
+// TaskTimeout overrides the default timeout of the WorkerPool for a single FutureFunc. A FutureFunc that does not complete in time resolves with a TimeoutError.
+func TaskTimeout(timeout time.Duration) TaskOption {
+	return func(t *task) {
+		t.timeout = timeout
+	}
+}
+
+// task is a ContextFutureFunc queued for execution by a worker go routine along with where its resulting Value is delivered and the metrics it is recorded against
+type task struct {
+	ctx          context.Context
+	fn           ContextFutureFunc
+	middleware   []Middleware
+	limiters     []*RateLimiter
+	rateLimitKey interface{}
+	timeout      time.Duration
+	deliver      func(Value)
+	metrics      []*poolMetrics
+	worker       *deque
+}
+
+func newTask(fn ContextFutureFunc, deliver func(Value), opts []TaskOption) *task {
+	t := &task{fn: fn, deliver: deliver}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+func withoutContext(fn FutureFunc) ContextFutureFunc {
+	return func(context.Context) (interface{}, error) {
+		return fn()
+	}
+}
+
+func (t *task) complete(v Value, latency time.Duration) {
+	t.finish(latency, v.Error)
+	t.deliver(v)
+}
+
+// finish records that the FutureFunc of the task has returned
+func (t *task) finish(latency time.Duration, err error) {
+	for _, m := range t.metrics {
+		m.finish(latency, err)
+	}
+}
+
+// withdraw reverts the submission of a task that was never queued
+func (t *task) withdraw() {
+	for _, m := range t.metrics {
+		m.withdraw()
+	}
+}
+
+// fail resolves a queued task with err without executing it
+func (t *task) fail(err error) {
+	for _, m := range t.metrics {
+		m.start()
+	}
+	t.complete(Value{Error: err}, 0)
+}
+
+// throttle waits for a token from each RateLimiter of the task before it is queued. TrySend does not wait and fails if any RateLimiter is out of tokens.
+// When a RateLimiter fails the tokens already taken from the RateLimiters before it are returned.
+// Returns true instead of an error if the TaskTimeout passed while waiting so that the task resolves with a TimeoutError.
+func (t *task) throttle(mode submitMode, ctx context.Context) (bool, error) {
+	if len(t.limiters) == 0 {
+		return false, nil
+	}
+	if mode == submitTry {
+		for i, limiter := range t.limiters {
+			if !limiter.take(t.rateLimitKey) {
+				for _, taken := range t.limiters[:i] {
+					taken.restore(t.rateLimitKey)
+				}
+				return false, RejectedTaskError{}
+			}
+		}
+		return false, nil
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	wait := ctx
+	if t.timeout > 0 {
+		var cancel context.CancelFunc
+		wait, cancel = context.WithTimeout(ctx, t.timeout)
+		defer cancel()
+	}
+	for i, limiter := range t.limiters {
+		if err := limiter.Wait(wait, t.rateLimitKey); err != nil {
+			for _, taken := range t.limiters[:i] {
+				taken.restore(t.rateLimitKey)
+			}
+			if ctx.Err() != nil {
+				return false, ctx.Err()
+			}
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// run executes the task and returns false if the worker go routine executing it was replaced after the task timed out. A task that timed out is resolved straight away but only
+// recorded as finished once its FutureFunc returns so that the metrics of the WorkerPool count it as busy while it still occupies a go routine.
+func (t *task) run(state *workerPoolState) bool {
+	for _, m := range t.metrics {
+		m.start()
+	}
+
+	var lock sync.Mutex
+	var finished, replaced bool
+	if t.timeout > 0 {
+		timer := time.AfterFunc(t.timeout, func() {
+			lock.Lock()
+			if finished {
+				lock.Unlock()
+				return
+			}
+			finished = true
+			if state.replace {
+				replaced = true
+				state.spawn()
+			}
+			lock.Unlock()
+			t.deliver(Value{Error: TimeoutError{t.timeout}})
+		})
+		defer timer.Stop()
+	}
+
+	start := time.Now()
+	v := trace(t.ctx, "futures.WorkerPool.task", func(ctx context.Context) (interface{}, error) {
+		if t.worker != nil {
+			ctx = context.WithValue(ctx, workerKey{}, t.worker)
+		}
+		if t.timeout == 0 {
+			return t.execute(ctx)
+		}
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, t.timeout)
+		defer cancel()
+		result, err := t.execute(ctx)
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, TimeoutError{t.timeout}
+		}
+		return result, err
+	})
+	latency := time.Since(start)
+
+	lock.Lock()
+	timedOut, detached := finished, replaced
+	finished = true
+	lock.Unlock()
+	if timedOut {
+		t.finish(latency, TimeoutError{t.timeout})
+	} else {
+		t.complete(v, latency)
+	}
+	return !detached
+}
+
+// execute executes the ContextFutureFunc wrapped in its Middleware
+func (t *task) execute(ctx context.Context) (interface{}, error) {
+	return applyMiddleware(func() (interface{}, error) {
+		return t.fn(ctx)
+	}, t.middleware)()
+}
@@ -0,0 +1,83 @@
+package futures
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTaskTimeout(t *testing.T) {
+	wp := NewFuturesWorkerPool(1, WithTaskTimeout(10*time.Millisecond))
+	defer wp.Close()
+
+	assert.Equal(t, true, wp.SendContextFunc(func(ctx context.Context) (interface{}, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}), "should be able to execute a ContextFutureFunc async")
+	value, _ := wp.Receive()
+	assert.Equal(t, TimeoutError{10 * time.Millisecond}, value.Error, "should resolve with a TimeoutError once the default timeout has passed")
+
+	wp.Send(func() (interface{}, error) {
+		time.Sleep(20 * time.Millisecond)
+		return "foobar", nil
+	}, TaskTimeout(time.Second))
+	value, _ = wp.Receive()
+	assert.Equal(t, "foobar", value.Data, "should be able to override the default timeout for a single FutureFunc")
+
+	out := make(chan Value, 1)
+	wp.Do(out, func() (interface{}, error) {
+		time.Sleep(20 * time.Millisecond)
+		return nil, nil
+	}, TaskTimeout(time.Millisecond))
+	value = <-out
+	assert.Equal(t, TimeoutError{time.Millisecond}, value.Error, "should resolve FutureFuncs executed with Do with a TimeoutError")
+
+	np := wp.Fork(1, WithTaskTimeout(time.Millisecond))
+	np.SendContextFunc(func(ctx context.Context) (interface{}, error) {
+		deadline, ok := ctx.Deadline()
+		assert.Equal(t, true, ok, "should give the ContextFutureFunc a context deadline")
+		<-time.After(time.Until(deadline) + 10*time.Millisecond)
+		return nil, nil
+	})
+	value, _ = np.Receive()
+	assert.Equal(t, TimeoutError{time.Millisecond}, value.Error, "should apply the default timeout of a NestedWorkerPool")
+}
+
+func TestWorkerReplacement(t *testing.T) {
+	release := make(chan bool)
+	defer close(release)
+	wedge := func() (interface{}, error) {
+		<-release
+		return nil, nil
+	}
+	run := func() (interface{}, error) {
+		return "foobar", nil
+	}
+
+	wp := NewFuturesWorkerPool(1, WithTaskTimeout(10*time.Millisecond), WithWorkerReplacement())
+	defer wp.Close()
+	wp.Send(wedge)
+	value, _ := wp.Receive()
+	assert.IsType(t, TimeoutError{}, value.Error, "should resolve a wedged FutureFunc with a TimeoutError")
+	wp.Send(run, TaskTimeout(time.Second))
+	value, _ = wp.Receive()
+	assert.Equal(t, "foobar", value.Data, "should replace a wedged worker so capacity recovers")
+	assert.Equal(t, 1, wp.(WorkerPool).state.size(), "should not change the number of workers when replacing a wedged worker")
+	stats := wp.Stats()
+	assert.Equal(t, 1, stats.BusyWorkers, "should count a wedged FutureFunc as busy until it returns")
+	assert.Equal(t, uint64(1), stats.Completed, "should not count a wedged FutureFunc as completed until it returns")
+
+	wp = NewFuturesWorkerPool(1, WithTaskTimeout(10*time.Millisecond))
+	defer wp.Close()
+	wp.Send(wedge)
+	wp.Receive()
+	out := make(chan Value, 1)
+	wp.Do(out, run)
+	select {
+	case <-out:
+		assert.Fail(t, "should not replace a wedged worker by default")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
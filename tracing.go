@@ -0,0 +1,131 @@
+package futures
+
+import (
+	"context"
+	"sync"
+)
+
+// Tracer creates Spans for Futures, chain steps, All and Map fan-outs and WorkerPool tasks. Its method set mirrors the Start method of an OpenTelemetry trace.Tracer so that one can be adapted with a small wrapper.
+type Tracer interface {
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is the subset of an OpenTelemetry trace.Span that is used to record the execution of a Future
+type Span interface {
+	RecordError(err error)
+	End()
+}
+
+type noopSpan struct{}
+
+func (noopSpan) RecordError(error) {}
+
+func (noopSpan) End() {}
+
+var tracer = struct {
+	sync.RWMutex
+	Tracer
+}{}
+
+// SetTracer sets the Tracer used to create Spans for all Futures and WorkerPools. Passing nil disables tracing.
+func SetTracer(t Tracer) {
+	tracer.Lock()
+	defer tracer.Unlock()
+	tracer.Tracer = t
+}
+
+// startSpan starts a Span that is a child of the Span in ctx if a Tracer has been set. The returned context is nil when there is nothing to propagate.
+func startSpan(ctx context.Context, name string) (context.Context, Span) {
+	tracer.RLock()
+	t := tracer.Tracer
+	tracer.RUnlock()
+	if t == nil {
+		return ctx, noopSpan{}
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return t.Start(ctx, name)
+}
+
+func tracing() bool {
+	tracer.RLock()
+	defer tracer.RUnlock()
+	return tracer.Tracer != nil
+}
+
+// trace executes fn within a Span and returns its result as a Value
+func trace(ctx context.Context, name string, fn ContextFutureFunc) Value {
+	v, _ := traced(ctx, name, fn)
+	return v
+}
+
+// traced executes fn within a Span and returns its result along with the Span's context so that it can be passed to any chained Futures with resolveWithContext
+func traced(ctx context.Context, name string, fn ContextFutureFunc) (Value, context.Context) {
+	ctx, span := startSpan(ctx, name)
+	defer span.End()
+	var v Value
+	if ctx == nil {
+		v.Data, v.Error = fn(context.Background())
+	} else {
+		v.Data, v.Error = fn(ctx)
+	}
+	if v.Error != nil {
+		span.RecordError(v.Error)
+	}
+	return v, ctx
+}
+
+// spanLink hands the span context of a Future to the Then, Catch or Finally chained on it. Links are kept in spanLinks keyed by the Future instead of in the Value so that Value stays a plain struct.
+// A link only exists while a chained step waits for the Future or while the go routine resolving the Future waits for its Value to be received.
+type spanLink struct {
+	lock sync.Mutex
+	ctx  context.Context
+}
+
+var spanLinks sync.Map
+
+// chainSpan is called when a Future is chained and returns the link that the span context of the Future is handed over with
+func chainSpan(f Future) *spanLink {
+	if !tracing() {
+		return nil
+	}
+	l, _ := spanLinks.LoadOrStore(f, &spanLink{})
+	return l.(*spanLink)
+}
+
+// parent returns the span context of f once its Value has been received and removes the link
+func (l *spanLink) parent(f Future) context.Context {
+	if l == nil {
+		return nil
+	}
+	spanLinks.Delete(f)
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return l.ctx
+}
+
+// publishSpan hands the span context of f to the step chained on it. When nothing has been chained yet the link is kept until the returned function is called once the Value of f has been received.
+func publishSpan(f Future, ctx context.Context) func() {
+	if ctx == nil {
+		return func() {}
+	}
+	l, loaded := spanLinks.LoadOrStore(f, &spanLink{ctx: ctx})
+	if loaded {
+		link := l.(*spanLink)
+		link.lock.Lock()
+		link.ctx = ctx
+		link.lock.Unlock()
+		return func() {}
+	}
+	return func() {
+		spanLinks.Delete(f)
+	}
+}
+
+// withContext sets the parent context of the Spans created for tasks sent to a NestedWorkerPool
+func withContext(ctx context.Context) WorkerPoolOption {
+	return func(c *workerPoolConfig) {
+		c.ctx = ctx
+	}
+}
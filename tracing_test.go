@@ -0,0 +1,127 @@
+package futures
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type spanKey struct{}
+
+type memorySpan struct {
+	name     string
+	parent   *memorySpan
+	err      error
+	exporter *memoryExporter
+}
+
+func (s *memorySpan) RecordError(err error) {
+	s.err = err
+}
+
+func (s *memorySpan) End() {
+	s.exporter.lock.Lock()
+	defer s.exporter.lock.Unlock()
+	s.exporter.spans = append(s.exporter.spans, s)
+}
+
+// memoryExporter is a Tracer that keeps ended spans in memory
+type memoryExporter struct {
+	lock  sync.Mutex
+	spans []*memorySpan
+}
+
+func (e *memoryExporter) Start(ctx context.Context, name string) (context.Context, Span) {
+	parent, _ := ctx.Value(spanKey{}).(*memorySpan)
+	span := &memorySpan{name: name, parent: parent, exporter: e}
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+func (e *memoryExporter) find(name string) []*memorySpan {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	var spans []*memorySpan
+	for _, span := range e.spans {
+		if span.name == name {
+			spans = append(spans, span)
+		}
+	}
+	return spans
+}
+
+func TestTracing(t *testing.T) {
+	exporter := &memoryExporter{}
+	SetTracer(exporter)
+	defer SetTracer(nil)
+
+	root, rootSpan := exporter.Start(context.Background(), "root")
+	value := <-NewFutureWithContext(root, func(ctx context.Context) (interface{}, error) {
+		return 1, nil
+	}).
+		Then(func(value interface{}) (interface{}, error) {
+			return nil, fmt.Errorf("some error")
+		}).
+		Then(func(value interface{}) (interface{}, error) {
+			return value, nil
+		}).
+		Catch(func(err error) (interface{}, error) {
+			return 2, nil
+		})
+	rootSpan.End()
+	assert.Equal(t, 2, value.Data, "should resolve traced Futures with the same values")
+
+	future := exporter.find("futures.NewFuture")
+	assert.Len(t, future, 1, "should create a span for NewFuture")
+	assert.Equal(t, rootSpan, future[0].parent, "should create the NewFuture span as a child of the span in the context")
+
+	then := exporter.find("futures.Then")
+	assert.Len(t, then, 1, "should not create spans for chain steps that are skipped")
+	assert.Equal(t, future[0], then[0].parent, "should create chain step spans as children of the previous step")
+	assert.Error(t, then[0].err, "should record errors returned by chain steps")
+
+	catch := exporter.find("futures.Catch")
+	assert.Len(t, catch, 1, "should create a span for Catch")
+	assert.Equal(t, then[0], catch[0].parent, "should create chain step spans as children of the last executed step")
+
+	resolved := NewFuture(func() (interface{}, error) {
+		return 1, nil
+	})
+	time.Sleep(20 * time.Millisecond)
+	chained := resolved.Finally(func() (interface{}, error) {
+		return nil, nil
+	})
+	<-chained
+	finally := exporter.find("futures.Finally")
+	assert.Len(t, finally, 1, "should create a span for Finally")
+	assert.Equal(t, exporter.find("futures.NewFuture")[1], finally[0].parent, "should create chain step spans as children of a Future that resolved before it was chained")
+	received := NewFuture(func() (interface{}, error) {
+		return 1, nil
+	})
+	<-received
+	for _, f := range []Future{resolved, chained, received} {
+		f := f
+		assert.Eventually(t, func() bool {
+			_, ok := spanLinks.Load(f)
+			return !ok
+		}, time.Second, 10*time.Millisecond, "should not keep the span context of a Future once its Value has been received")
+	}
+	assert.Equal(t, Value{"foobar", nil}, Value{Data: "foobar"}, "should not carry the span context in the Value")
+
+	wp := NewFuturesWorkerPool(2)
+	defer wp.Close()
+	<-MapWithWorkerPool([]interface{}{1, 2, 3}, func(value interface{}) (interface{}, error) {
+		return value, nil
+	}, 2, wp)
+
+	fanout := exporter.find("futures.Map")
+	assert.Len(t, fanout, 1, "should create a span for Map")
+	tasks := exporter.find("futures.WorkerPool.task")
+	assert.Len(t, tasks, 3, "should create a span for each WorkerPool task")
+	for _, task := range tasks {
+		assert.Equal(t, fanout[0], task.parent, "should create WorkerPool task spans as children of the fan-out span")
+	}
+}
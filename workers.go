@@ -1,220 +1,591 @@
-package futures
-
-import (
-	"sync"
-)
-
-type skipOutChannel struct{}
-
-func makeWorker(in chan FutureFunc, out chan Value, kill chan bool) {
-	go func() {
-	worker:
-		for {
-			select {
-			case _, ok := <-kill:
-				if !ok {
-					break worker
-				}
-			default:
-			}
-			fn := <-in
-			if fn != nil {
-				result, err := fn()
-				if _, ok := result.(skipOutChannel); !ok {
-					out <- Value{result, err}
-				}
-			}
-		}
-	}()
-}
-
-// WorkerPoolInterface defines methods implemented by structs WorkerPool and NestedWorkerPool. These combined functionalities allow of asynchronous execution of FutureFuncs.
-type WorkerPoolInterface interface {
-	Send(FutureFunc) bool
-	Receive() (Value, bool)
-	Close() bool
-	Fork(int) WorkerPoolInterface
-	Do(chan Value, FutureFunc) bool
-}
-
-// WorkerPool manages go routines used for executing FutureFuncs
-type WorkerPool struct {
-	in        chan FutureFunc
-	out       Future
-	kill      chan bool
-	closeLock *sync.Mutex
-	sendLock  *sync.Mutex
-}
-
-// Send pushes a FutureFunc to a channel that worker go routines poll and execute from
-func (w WorkerPool) Send(fn FutureFunc) bool {
-	w.sendLock.Lock()
-	defer w.sendLock.Unlock()
-	select {
-	case _, ok := <-w.kill:
-		if !ok {
-			return false
-		}
-	default:
-	}
-	w.in <- fn
-	return true
-}
-
-// Receive listens on the out channel and waits for a value to be returned from a FutureFunc execution
-func (w WorkerPool) Receive() (Value, bool) {
-	if v, ok := <-w.out; ok {
-		return v, true
-	}
-	return Value{}, false
-}
-
-// Close kills all worker go routines and closes in and out channels
-func (w WorkerPool) Close() bool {
-	w.closeLock.Lock()
-	defer w.closeLock.Unlock()
-	select {
-	case _, ok := <-w.kill:
-		if !ok {
-			return false
-		}
-	default:
-	}
-	close(w.kill)
-	return true
-}
-
-// Fork creates a NestedWorkerPool from parent WorkerPool which shares an in channel and worker go routines
-func (w WorkerPool) Fork(concurrency int) WorkerPoolInterface {
-	out := make(chan Value, concurrency)
-	kill := make(chan bool)
-	go func() {
-		select {
-		case _, ok := <-out:
-			if !ok {
-				return
-			}
-		default:
-		}
-		select {
-		case <-kill:
-			close(out)
-		case <-w.kill:
-			close(out)
-		}
-	}()
-	return NestedWorkerPool{
-		WorkerPool: WorkerPool{
-			in:        w.in,
-			kill:      w.kill,
-			closeLock: w.closeLock,
-			sendLock:  w.sendLock,
-		},
-		out:  out,
-		kill: kill,
-	}
-}
-
-// Do executes a FutureFunc in a worker go routine but writes the returned value to the specified out channel
-func (w WorkerPool) Do(out chan Value, fn FutureFunc) bool {
-	return w.Send(func() (interface{}, error) {
-		result, err := fn()
-		out <- Value{result, err}
-		return skipOutChannel{}, nil
-	})
-}
-
-// NestedWorkerPool shares resources with a parent WorkerPool but can be indepedently closed and only receives values directly sent to it
-type NestedWorkerPool struct {
-	WorkerPool
-	out  chan Value
-	kill chan bool
-}
-
-// Send pushes a FutureFunc to a parent WorkerPool but writes the result to the nested out channel
-func (n NestedWorkerPool) Send(fn FutureFunc) bool {
-	select {
-	case _, ok := <-n.kill:
-		if !ok {
-			return false
-		}
-	default:
-	}
-	return n.WorkerPool.Send(func() (interface{}, error) {
-		result, err := fn()
-		select {
-		case _, ok := <-n.kill:
-			if !ok {
-				return skipOutChannel{}, nil
-			}
-		default:
-		}
-		n.out <- Value{result, err}
-		return skipOutChannel{}, nil
-	})
-}
-
-// Receive listens on the out channel and waits for a value to be returned from a FutureFunc execution
-func (n NestedWorkerPool) Receive() (Value, bool) {
-	if v, ok := <-n.out; ok {
-		return v, true
-	}
-	return Value{}, false
-}
-
-// Close closes nested out channel and blocks any subsequent writes to the parent in channel. Closing will not effect parent WorkerPool resources
-func (n NestedWorkerPool) Close() bool {
-	n.closeLock.Lock()
-	defer n.closeLock.Unlock()
-	select {
-	case _, ok := <-n.WorkerPool.kill:
-		if !ok {
-			return false
-		}
-	default:
-	}
-	select {
-	case _, ok := <-n.kill:
-		if !ok {
-			return false
-		}
-	default:
-	}
-	close(n.kill)
-	return true
-}
-
-// Do executes a FutureFunc in a parent worker go routine but writes the returned value to the specified out channel
-func (n NestedWorkerPool) Do(out chan Value, fn FutureFunc) bool {
-	select {
-	case _, ok := <-n.kill:
-		if !ok {
-			return false
-		}
-	default:
-	}
-	return n.WorkerPool.Do(out, fn)
-}
-
-// NewFuturesWorkerPool creates a WorkerPool with the specified number of workers as define by the concurrency argument
-func NewFuturesWorkerPool(concurrency int) WorkerPoolInterface {
-	in := make(chan FutureFunc, concurrency)
-	out := make(chan Value, concurrency)
-	kill := make(chan bool)
-	closeChannelLock := sync.Mutex{}
-	sendLock := sync.Mutex{}
-
-	go func() {
-		<-kill
-		sendLock.Lock()
-		defer sendLock.Unlock()
-		close(in)
-		close(out)
-	}()
-
-	for i := 0; i < concurrency; i++ {
-		makeWorker(in, out, kill)
-	}
-
-	return WorkerPool{in, out, kill, &closeChannelLock, &sendLock}
-}
+package futures
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// WorkerPoolOption configures optional behavior of a WorkerPool created with NewFuturesWorkerPool
+type WorkerPoolOption func(*workerPoolConfig)
+
+type workerPoolConfig struct {
+	autoscale      bool
+	minWorkers     int
+	maxWorkers     int
+	idleTimeout    time.Duration
+	middleware     []Middleware
+	ctx            context.Context
+	taskTimeout    time.Duration
+	replace        bool
+	limiters       []*RateLimiter
+	queue          int
+	rejection      RejectionPolicy
+	stealing       bool
+	detectInterval time.Duration
+	onDeadlock     func(DeadlockError)
+}
+
+// Middleware wraps a FutureFunc executed by a WorkerPool with additional behavior
+type Middleware func(FutureFunc) FutureFunc
+
+// Use wraps every FutureFunc executed by a WorkerPool with the provided Middleware with the first Middleware being the outermost. NestedWorkerPools inherit the Middleware of their parent and Middleware passed to Fork is applied inside of it.
+func Use(middleware ...Middleware) WorkerPoolOption {
+	return func(c *workerPoolConfig) {
+		c.middleware = append(c.middleware, middleware...)
+	}
+}
+
+func applyMiddleware(fn FutureFunc, middleware []Middleware) FutureFunc {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		fn = middleware[i](fn)
+	}
+	return fn
+}
+
+// WithTaskTimeout sets the default TaskTimeout for every FutureFunc executed by a WorkerPool or NestedWorkerPool
+func WithTaskTimeout(timeout time.Duration) WorkerPoolOption {
+	return func(c *workerPoolConfig) {
+		c.taskTimeout = timeout
+	}
+}
+
+// WithWorkerReplacement starts a replacement worker go routine whenever a FutureFunc exceeds its TaskTimeout so that a WorkerPool recovers its capacity while the wedged worker finishes in the background
+func WithWorkerReplacement() WorkerPoolOption {
+	return func(c *workerPoolConfig) {
+		c.replace = true
+	}
+}
+
+// WithAutoscaling allows a WorkerPool to grow up to max workers while FutureFuncs are queued faster than they are executed and to shrink back down to min workers once workers have been idle for idleTimeout
+func WithAutoscaling(min, max int, idleTimeout time.Duration) WorkerPoolOption {
+	return func(c *workerPoolConfig) {
+		c.autoscale = true
+		c.minWorkers = min
+		c.maxWorkers = max
+		c.idleTimeout = idleTimeout
+	}
+}
+
+// workerPoolState is shared by a WorkerPool and all of its copies and forks and tracks the worker go routines that are currently running
+type workerPoolState struct {
+	workerPoolConfig
+	lock       sync.Mutex
+	workers    int
+	idle       int
+	retire     chan struct{}
+	spawn      func()
+	scheduler  *scheduler
+	goroutines *goroutines
+	metrics    *poolMetrics
+	forks      []*poolMetrics
+	forked     int
+}
+
+// addFork registers the metrics of a new NestedWorkerPool so they are reported with the Stats of the parent WorkerPool
+func (s *workerPoolState) addFork() *poolMetrics {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.forked++
+	m := newPoolMetrics(fmt.Sprintf("nested-%d", s.forked))
+	s.forks = append(s.forks, m)
+	return m
+}
+
+func (s *workerPoolState) removeFork(m *poolMetrics) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for i, fork := range s.forks {
+		if fork == m {
+			s.forks = append(s.forks[:i], s.forks[i+1:]...)
+			return
+		}
+	}
+}
+
+func (s *workerPoolState) setIdle(delta int) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.idle += delta
+}
+
+func (s *workerPoolState) size() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.workers
+}
+
+// shrink removes an idle worker if the pool is above its minimum size
+func (s *workerPoolState) shrink() bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.workers <= s.minWorkers {
+		return false
+	}
+	s.workers--
+	s.idle--
+	return true
+}
+
+// next blocks until the worker receives a task to execute and returns false if the worker should exit instead
+func (s *workerPoolState) next(in chan *task, kill chan bool, wake chan struct{}) (*task, bool) {
+	var idle <-chan time.Time
+	if s.idleTimeout > 0 {
+		timer := time.NewTimer(s.idleTimeout)
+		defer timer.Stop()
+		idle = timer.C
+	}
+	s.setIdle(1)
+	select {
+	case <-kill:
+		return nil, false
+	case <-s.retire:
+		s.setIdle(-1)
+		return nil, false
+	case <-wake:
+		s.setIdle(-1)
+		return nil, true
+	case <-idle:
+		if s.shrink() {
+			return nil, false
+		}
+		s.setIdle(-1)
+		return nil, true
+	case t := <-in:
+		s.setIdle(-1)
+		return t, true
+	}
+}
+
+func makeWorker(in chan *task, kill chan bool, state *workerPoolState) {
+	go func() {
+		defer state.goroutines.enter()()
+		local := state.scheduler.acquire()
+		defer state.scheduler.release(local)
+		for {
+			select {
+			case _, ok := <-kill:
+				if !ok {
+					return
+				}
+			default:
+			}
+			t := local.take()
+			if t == nil {
+				var ok bool
+				if t, ok = state.next(in, kill, state.scheduler.wakeup()); !ok {
+					return
+				}
+			}
+			if t == nil {
+				continue
+			}
+			t.worker = local
+			if !t.run(state) {
+				return
+			}
+			state.goroutines.forget()
+		}
+	}()
+}
+
+// ClosedWorkerPoolError implements the error interface and returns a standard error for a FutureFunc that could not be sent to a WorkerPool because it has been closed
+type ClosedWorkerPoolError struct{}
+
+// Error returns an error message for ClosedWorkerPoolError
+func (ClosedWorkerPoolError) Error() string {
+	return "worker pool has been closed"
+}
+
+// WorkerPoolInterface defines methods implemented by structs WorkerPool and NestedWorkerPool. These combined functionalities allow of asynchronous execution of FutureFuncs.
+type WorkerPoolInterface interface {
+	Send(FutureFunc, ...TaskOption) bool
+	TrySend(FutureFunc, ...TaskOption) bool
+	SendWithContext(context.Context, FutureFunc, ...TaskOption) error
+	Spawn(context.Context, ContextFutureFunc, ...TaskOption) Future
+	Await(context.Context, Future) Value
+	SendContextFunc(ContextFutureFunc, ...TaskOption) bool
+	Receive() (Value, bool)
+	Close() bool
+	Fork(int, ...WorkerPoolOption) WorkerPoolInterface
+	Do(chan Value, FutureFunc, ...TaskOption) bool
+	Resize(int) bool
+	Stats() WorkerPoolStats
+}
+
+// WorkerPool manages go routines used for executing FutureFuncs
+type WorkerPool struct {
+	in        chan *task
+	out       chan Value
+	kill      chan bool
+	closeLock *sync.Mutex
+	sendLock  *sync.RWMutex
+	state     *workerPoolState
+}
+
+// grow starts an additional worker go routine if FutureFuncs are backing up in the queue and the pool has not reached its maximum size
+func (w WorkerPool) grow() {
+	w.state.lock.Lock()
+	defer w.state.lock.Unlock()
+	if w.state.autoscale && w.state.workers < w.state.maxWorkers && len(w.in) >= w.state.idle {
+		w.state.workers++
+		w.state.spawn()
+	}
+}
+
+func (w WorkerPool) closed() bool {
+	select {
+	case _, ok := <-w.kill:
+		return !ok
+	default:
+		return false
+	}
+}
+
+func (w WorkerPool) send(t *task) bool {
+	return w.submit(t, submitPolicy, nil) == nil
+}
+
+// newTask returns a task that delivers its Value to the out channel of the WorkerPool. The Value is dropped once the WorkerPool is closed since out is closed along with it.
+func (w WorkerPool) newTask(fn ContextFutureFunc, opts []TaskOption) *task {
+	return newTask(fn, func(v Value) {
+		w.sendLock.RLock()
+		defer w.sendLock.RUnlock()
+		if w.closed() {
+			return
+		}
+		select {
+		case w.out <- v:
+		case <-w.kill:
+		}
+	}, opts)
+}
+
+// Send pushes a FutureFunc to a channel that worker go routines poll and execute from. When the queue is full the RejectionPolicy of the WorkerPool applies. Returns false without delivering a Value if the WorkerPool has been closed.
+func (w WorkerPool) Send(fn FutureFunc, opts ...TaskOption) bool {
+	return w.SendContextFunc(withoutContext(fn), opts...)
+}
+
+// SendContextFunc pushes a ContextFutureFunc to a channel that worker go routines poll and execute from. The context passed to the ContextFutureFunc is cancelled once its TaskTimeout has passed.
+func (w WorkerPool) SendContextFunc(fn ContextFutureFunc, opts ...TaskOption) bool {
+	return w.send(w.newTask(fn, opts))
+}
+
+// TrySend pushes a FutureFunc to the queue without blocking and returns false if the queue is full or the WorkerPool has been closed
+func (w WorkerPool) TrySend(fn FutureFunc, opts ...TaskOption) bool {
+	return w.submit(w.newTask(withoutContext(fn), opts), submitTry, nil) == nil
+}
+
+// SendWithContext pushes a FutureFunc to the queue and blocks while the queue is full. Returns the error of the context if it is done before the FutureFunc could be queued or a ClosedWorkerPoolError if the WorkerPool has been closed.
+func (w WorkerPool) SendWithContext(ctx context.Context, fn FutureFunc, opts ...TaskOption) error {
+	return w.submit(w.newTask(withoutContext(fn), opts), submitWait, ctx)
+}
+
+// Receive listens on the out channel and waits for a value to be returned from a FutureFunc execution
+func (w WorkerPool) Receive() (Value, bool) {
+	if v, ok := <-w.out; ok {
+		return v, true
+	}
+	return Value{}, false
+}
+
+// Close kills all worker go routines and closes in and out channels
+func (w WorkerPool) Close() bool {
+	w.closeLock.Lock()
+	defer w.closeLock.Unlock()
+	select {
+	case _, ok := <-w.kill:
+		if !ok {
+			return false
+		}
+	default:
+	}
+	close(w.kill)
+	return true
+}
+
+// Fork creates a NestedWorkerPool from parent WorkerPool which shares an in channel and worker go routines. Only the Middleware, TaskTimeout and RateLimiters of the WorkerPoolOptions apply to a NestedWorkerPool.
+func (w WorkerPool) Fork(concurrency int, opts ...WorkerPoolOption) WorkerPoolInterface {
+	return w.fork(concurrency, workerPoolConfig{}, opts)
+}
+
+func (w WorkerPool) fork(concurrency int, config workerPoolConfig, opts []WorkerPoolOption) WorkerPoolInterface {
+	config.middleware = append([]Middleware{}, config.middleware...)
+	config.limiters = append([]*RateLimiter{}, config.limiters...)
+	for _, opt := range opts {
+		opt(&config)
+	}
+	out := make(chan Value, concurrency)
+	kill := make(chan bool)
+	outLock := &sync.RWMutex{}
+	metrics := w.state.addFork()
+	go func() {
+		defer w.state.removeFork(metrics)
+		select {
+		case <-kill:
+		case <-w.kill:
+		}
+		outLock.Lock()
+		defer outLock.Unlock()
+		close(out)
+	}()
+	return NestedWorkerPool{
+		WorkerPool: WorkerPool{
+			in:        w.in,
+			kill:      w.kill,
+			closeLock: w.closeLock,
+			sendLock:  w.sendLock,
+			state:     w.state,
+		},
+		out:     out,
+		kill:    kill,
+		outLock: outLock,
+		metrics: metrics,
+		config:  config,
+	}
+}
+
+// Do executes a FutureFunc in a worker go routine but writes the returned value to the specified out channel
+func (w WorkerPool) Do(out chan Value, fn FutureFunc, opts ...TaskOption) bool {
+	return w.send(newTask(withoutContext(fn), func(v Value) {
+		out <- v
+	}, opts))
+}
+
+// Stats returns a snapshot of the counters and task latencies of the WorkerPool along with the stats of each of its open NestedWorkerPools
+func (w WorkerPool) Stats() WorkerPoolStats {
+	stats := w.state.metrics.snapshot()
+	stats.Workers = w.state.size()
+	w.state.lock.Lock()
+	forks := append([]*poolMetrics{}, w.state.forks...)
+	w.state.lock.Unlock()
+	for _, m := range forks {
+		nested := m.snapshot()
+		nested.Workers = stats.Workers
+		stats.Nested = append(stats.Nested, nested)
+	}
+	return stats
+}
+
+// Resize changes the number of worker go routines executing FutureFuncs. When autoscaling is enabled the new size is kept within the configured bounds. Returns false if the pool has been closed.
+func (w WorkerPool) Resize(concurrency int) bool {
+	w.closeLock.Lock()
+	defer w.closeLock.Unlock()
+	select {
+	case _, ok := <-w.kill:
+		if !ok {
+			return false
+		}
+	default:
+	}
+	w.state.lock.Lock()
+	defer w.state.lock.Unlock()
+	if w.state.autoscale {
+		if concurrency < w.state.minWorkers {
+			concurrency = w.state.minWorkers
+		}
+		if concurrency > w.state.maxWorkers {
+			concurrency = w.state.maxWorkers
+		}
+	} else if concurrency < 1 {
+		return false
+	}
+	for ; w.state.workers < concurrency; w.state.workers++ {
+		w.state.spawn()
+	}
+	for ; w.state.workers > concurrency; w.state.workers-- {
+		go func() {
+			select {
+			case w.state.retire <- struct{}{}:
+			case <-w.kill:
+			}
+		}()
+	}
+	return true
+}
+
+// NestedWorkerPool shares resources with a parent WorkerPool but can be indepedently closed and only receives values directly sent to it
+type NestedWorkerPool struct {
+	WorkerPool
+	out     chan Value
+	kill    chan bool
+	outLock *sync.RWMutex
+	metrics *poolMetrics
+	config  workerPoolConfig
+}
+
+func (n NestedWorkerPool) send(t *task) bool {
+	return n.submit(t, submitPolicy, nil) == nil
+}
+
+func (n NestedWorkerPool) submit(t *task, mode submitMode, ctx context.Context) error {
+	select {
+	case _, ok := <-n.kill:
+		if !ok {
+			return ClosedWorkerPoolError{}
+		}
+	default:
+	}
+	t.ctx = n.config.ctx
+	t.middleware = append(append([]Middleware{}, n.config.middleware...), t.middleware...)
+	t.limiters = append(t.limiters, n.config.limiters...)
+	if t.timeout == 0 {
+		t.timeout = n.config.taskTimeout
+	}
+	t.metrics = append(t.metrics, n.metrics)
+	return n.WorkerPool.submit(t, mode, ctx)
+}
+
+// newTask returns a task that delivers its Value to the nested out channel. The Value is dropped once the NestedWorkerPool or its parent is closed since out is closed along with them.
+func (n NestedWorkerPool) newTask(fn ContextFutureFunc, opts []TaskOption) *task {
+	return newTask(fn, func(v Value) {
+		n.outLock.RLock()
+		defer n.outLock.RUnlock()
+		select {
+		case <-n.kill:
+			return
+		case <-n.WorkerPool.kill:
+			return
+		default:
+		}
+		select {
+		case n.out <- v:
+		case <-n.kill:
+		case <-n.WorkerPool.kill:
+		}
+	}, opts)
+}
+
+// Send pushes a FutureFunc to a parent WorkerPool but writes the result to the nested out channel
+func (n NestedWorkerPool) Send(fn FutureFunc, opts ...TaskOption) bool {
+	return n.SendContextFunc(withoutContext(fn), opts...)
+}
+
+// SendContextFunc pushes a ContextFutureFunc to a parent WorkerPool but writes the result to the nested out channel
+func (n NestedWorkerPool) SendContextFunc(fn ContextFutureFunc, opts ...TaskOption) bool {
+	return n.send(n.newTask(fn, opts))
+}
+
+// TrySend pushes a FutureFunc to the queue of the parent WorkerPool without blocking and returns false if the queue is full or the NestedWorkerPool has been closed
+func (n NestedWorkerPool) TrySend(fn FutureFunc, opts ...TaskOption) bool {
+	return n.submit(n.newTask(withoutContext(fn), opts), submitTry, nil) == nil
+}
+
+// SendWithContext pushes a FutureFunc to the queue of the parent WorkerPool and blocks while the queue is full. Returns the error of the context if it is done before the FutureFunc could be queued or a ClosedWorkerPoolError if the NestedWorkerPool has been closed.
+func (n NestedWorkerPool) SendWithContext(ctx context.Context, fn FutureFunc, opts ...TaskOption) error {
+	return n.submit(n.newTask(withoutContext(fn), opts), submitWait, ctx)
+}
+
+// Receive listens on the out channel and waits for a value to be returned from a FutureFunc execution
+func (n NestedWorkerPool) Receive() (Value, bool) {
+	if v, ok := <-n.out; ok {
+		return v, true
+	}
+	return Value{}, false
+}
+
+// Close closes nested out channel and blocks any subsequent writes to the parent in channel. Closing will not effect parent WorkerPool resources
+func (n NestedWorkerPool) Close() bool {
+	n.closeLock.Lock()
+	defer n.closeLock.Unlock()
+	select {
+	case _, ok := <-n.WorkerPool.kill:
+		if !ok {
+			return false
+		}
+	default:
+	}
+	select {
+	case _, ok := <-n.kill:
+		if !ok {
+			return false
+		}
+	default:
+	}
+	close(n.kill)
+	return true
+}
+
+// Do executes a FutureFunc in a parent worker go routine but writes the returned value to the specified out channel
+func (n NestedWorkerPool) Do(out chan Value, fn FutureFunc, opts ...TaskOption) bool {
+	return n.send(newTask(withoutContext(fn), func(v Value) {
+		out <- v
+	}, opts))
+}
+
+// Fork creates a NestedWorkerPool that shares the worker go routines of the parent WorkerPool and inherits the Middleware, TaskTimeout and RateLimiters of the NestedWorkerPool
+func (n NestedWorkerPool) Fork(concurrency int, opts ...WorkerPoolOption) WorkerPoolInterface {
+	return n.WorkerPool.fork(concurrency, n.config, opts)
+}
+
+// Resize always returns false since a NestedWorkerPool shares the worker go routines of its parent WorkerPool
+func (n NestedWorkerPool) Resize(concurrency int) bool {
+	return false
+}
+
+// Stats returns a snapshot of the counters and task latencies of FutureFuncs sent to the NestedWorkerPool
+func (n NestedWorkerPool) Stats() WorkerPoolStats {
+	stats := n.metrics.snapshot()
+	stats.Workers = n.state.size()
+	return stats
+}
+
+// NewFuturesWorkerPool creates a WorkerPool with the specified number of workers as define by the concurrency argument
+func NewFuturesWorkerPool(concurrency int, opts ...WorkerPoolOption) WorkerPoolInterface {
+	config := workerPoolConfig{minWorkers: concurrency, maxWorkers: concurrency, queue: -1}
+	for _, opt := range opts {
+		opt(&config)
+	}
+	if config.autoscale {
+		if concurrency < config.minWorkers {
+			concurrency = config.minWorkers
+		}
+		if concurrency > config.maxWorkers {
+			concurrency = config.maxWorkers
+		}
+	}
+
+	if config.queue < 0 {
+		config.queue = concurrency
+	}
+
+	in := make(chan *task, config.queue)
+	out := make(chan Value, concurrency)
+	kill := make(chan bool)
+	closeChannelLock := sync.Mutex{}
+	sendLock := sync.RWMutex{}
+	state := &workerPoolState{
+		workerPoolConfig: config,
+		workers:          concurrency,
+		retire:           make(chan struct{}),
+		metrics:          newPoolMetrics("root"),
+	}
+	if config.stealing {
+		state.scheduler = newScheduler(config.maxWorkers)
+	}
+	if config.detectInterval > 0 {
+		state.goroutines = &goroutines{ids: map[int]bool{}, waits: map[int][]*dependency{}}
+		go state.detectDeadlocks(kill)
+	}
+	state.spawn = func() {
+		makeWorker(in, kill, state)
+	}
+
+	go func() {
+		<-kill
+		sendLock.Lock()
+		defer sendLock.Unlock()
+		for _, t := range state.scheduler.drain() {
+			go t.fail(ClosedWorkerPoolError{})
+		}
+		close(in)
+		close(out)
+	}()
+
+	for i := 0; i < concurrency; i++ {
+		state.spawn()
+	}
+
+	return WorkerPool{in, out, kill, &closeChannelLock, &sendLock, state}
+}
@@ -2,6 +2,7 @@ package futures
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -114,3 +115,95 @@ func TestNestedWorkerPool(t *testing.T) {
 
 	assert.Equal(t, false, np.Close(), "should return false if Close is called after parents workers have alreay been closed")
 }
+
+func TestWorkerPoolAutoscaling(t *testing.T) {
+	wp := NewFuturesWorkerPool(1, WithAutoscaling(1, 3, 20*time.Millisecond))
+	defer wp.Close()
+	state := wp.(WorkerPool).state
+
+	release := make(chan bool)
+	for i := 0; i < 5; i++ {
+		go wp.Send(func() (interface{}, error) {
+			<-release
+			return nil, nil
+		})
+	}
+	assert.Eventually(t, func() bool {
+		return state.size() == 3
+	}, time.Second, 10*time.Millisecond, "should grow to the maximum number of workers while FutureFuncs are queued")
+
+	close(release)
+	for i := 0; i < 5; i++ {
+		wp.Receive()
+	}
+	assert.Eventually(t, func() bool {
+		return state.size() == 1
+	}, time.Second, 10*time.Millisecond, "should shrink to the minimum number of workers once idle")
+
+	assert.Equal(t, true, wp.Resize(10), "should be able to resize an open WorkerPool")
+	assert.Equal(t, 3, state.size(), "should keep resized WorkerPool within autoscaling bounds")
+}
+
+func TestWorkerPoolResize(t *testing.T) {
+	wp := NewFuturesWorkerPool(1)
+	state := wp.(WorkerPool).state
+
+	assert.Equal(t, true, wp.Resize(3), "should be able to resize an open WorkerPool")
+	assert.Equal(t, 3, state.size(), "should add workers when resized up")
+
+	assert.Equal(t, true, wp.Resize(2), "should be able to resize an open WorkerPool")
+	assert.Equal(t, 2, state.size(), "should remove workers when resized down")
+
+	for i := 0; i < 4; i++ {
+		i := i
+		go wp.Send(func() (interface{}, error) {
+			return i, nil
+		})
+	}
+	for i := 0; i < 4; i++ {
+		_, ok := wp.Receive()
+		assert.Equal(t, true, ok, "should continue to execute FutureFuncs after being resized")
+	}
+
+	assert.Equal(t, false, wp.Resize(0), "should return false if resized below one worker")
+	assert.Equal(t, false, wp.Fork(1).Resize(2), "should return false when resizing a NestedWorkerPool")
+
+	wp.Close()
+	assert.Equal(t, false, wp.Resize(2), "should return false if Resize is called after workers have been closed")
+}
+
+func TestWorkerPoolMiddleware(t *testing.T) {
+	// tag appends its name once the wrapped FutureFunc returns so the innermost Middleware is listed first
+	tag := func(name string) Middleware {
+		return func(fn FutureFunc) FutureFunc {
+			return func() (interface{}, error) {
+				result, err := fn()
+				return append(result.([]string), name), err
+			}
+		}
+	}
+	run := func() (interface{}, error) {
+		return []string{}, nil
+	}
+
+	wp := NewFuturesWorkerPool(2, Use(tag("outer"), tag("inner")))
+	defer wp.Close()
+
+	wp.Send(run)
+	value, _ := wp.Receive()
+	assert.Equal(t, []string{"inner", "outer"}, value.Data, "should wrap FutureFuncs sent to the WorkerPool with Middleware")
+
+	out := make(chan Value, 1)
+	wp.Do(out, run)
+	value = <-out
+	assert.Equal(t, []string{"inner", "outer"}, value.Data, "should wrap FutureFuncs executed with Do with Middleware")
+
+	np := wp.Fork(1, Use(tag("nested")))
+	np.Send(run)
+	value, _ = np.Receive()
+	assert.Equal(t, []string{"nested", "inner", "outer"}, value.Data, "should apply Middleware of the NestedWorkerPool inside of the inherited Middleware")
+
+	np.Fork(1, Use(tag("child"))).Do(out, run)
+	value = <-out
+	assert.Equal(t, []string{"child", "nested", "inner", "outer"}, value.Data, "should inherit Middleware when forking a NestedWorkerPool")
+}
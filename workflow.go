@@ -0,0 +1,300 @@
+package futures
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// CheckpointStore persists the checkpoints of a Workflow. FileStore, LogStore and MemoryStore are included and any other key value store can be used, for example a bucket of a BoltDB database or a table of a SQLite database keyed by the checkpoint key.
+type CheckpointStore interface {
+	Load(key string) ([]byte, bool, error)
+	Save(key string, data []byte) error
+	Delete(key string) error
+}
+
+// FileStore is a CheckpointStore that saves each checkpoint as a file in a directory
+type FileStore struct {
+	dir string
+}
+
+// NewFileStore creates a FileStore that saves checkpoints in dir and creates dir if it does not exist
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &FileStore{dir}, nil
+}
+
+func (s *FileStore) path(key string) string {
+	return filepath.Join(s.dir, url.PathEscape(key))
+}
+
+// Load returns the checkpoint saved for key and false if there is none
+func (s *FileStore) Load(key string) ([]byte, bool, error) {
+	data, err := ioutil.ReadFile(s.path(key))
+	if os.IsNotExist(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return data, true, nil
+}
+
+// Save writes the checkpoint to a temporary file and renames it so that a crash never leaves a partially written checkpoint behind
+func (s *FileStore) Save(key string, data []byte) error {
+	tmp, err := ioutil.TempFile(s.dir, ".checkpoint-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path(key))
+}
+
+// Delete removes the checkpoint saved for key
+func (s *FileStore) Delete(key string) error {
+	if err := os.Remove(s.path(key)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// MemoryStore is a CheckpointStore that keeps checkpoints in memory. Checkpoints do not survive a restart so it is meant for tests.
+type MemoryStore struct {
+	lock sync.Mutex
+	data map[string][]byte
+}
+
+// NewMemoryStore creates an empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{data: map[string][]byte{}}
+}
+
+// Load returns the checkpoint saved for key and false if there is none
+func (s *MemoryStore) Load(key string) ([]byte, bool, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	data, ok := s.data[key]
+	return data, ok, nil
+}
+
+// Save stores a copy of the checkpoint
+func (s *MemoryStore) Save(key string, data []byte) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.data[key] = append([]byte{}, data...)
+	return nil
+}
+
+// Delete removes the checkpoint saved for key
+func (s *MemoryStore) Delete(key string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	delete(s.data, key)
+	return nil
+}
+
+// Codec serializes the results of the steps of a Workflow
+type Codec interface {
+	Encode(interface{}) ([]byte, error)
+	Decode([]byte) (interface{}, error)
+}
+
+// GobCodec is a Codec that preserves the types of results. Types other than the builtin types have to be registered with gob.Register.
+type GobCodec struct{}
+
+// Encode serializes v with encoding/gob
+func (GobCodec) Encode(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(&v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Decode deserializes a value encoded by Encode
+func (GobCodec) Decode(data []byte) (interface{}, error) {
+	var v interface{}
+	err := gob.NewDecoder(bytes.NewReader(data)).Decode(&v)
+	return v, err
+}
+
+// JSONCodec is a Codec that saves results as JSON. Results are decoded into the generic JSON types so numbers become float64 and structs become maps.
+type JSONCodec struct{}
+
+// Encode serializes v with encoding/json
+func (JSONCodec) Encode(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Decode deserializes a value encoded by Encode
+func (JSONCodec) Decode(data []byte) (interface{}, error) {
+	var v interface{}
+	err := json.Unmarshal(data, &v)
+	return v, err
+}
+
+// DurableFunc specifies the function signature expected for a step of a Workflow. The idempotency key is the same every time the step of a run is executed and should be passed to
+// external services so that a step that is retried after a crash is not applied twice.
+type DurableFunc func(idempotencyKey string, argv interface{}) (interface{}, error)
+
+// DuplicateStepError implements the error interface and is returned when a Workflow contains more than one step with the same name
+type DuplicateStepError struct {
+	Step string
+}
+
+// Error returns an error message for DuplicateStepError
+func (e DuplicateStepError) Error() string {
+	return fmt.Sprintf("workflow step %q was added more than once", e.Step)
+}
+
+// CheckpointError implements the error interface and is returned when the checkpoint of a step of a Workflow could not be serialized, saved or loaded
+type CheckpointError struct {
+	Step string
+	Err  error
+}
+
+// Error returns an error message for CheckpointError
+func (e CheckpointError) Error() string {
+	return fmt.Sprintf("checkpoint of workflow step %q failed: %s", e.Step, e.Err)
+}
+
+// Unwrap returns the error of the CheckpointStore or Codec
+func (e CheckpointError) Unwrap() error {
+	return e.Err
+}
+
+// WorkflowOption configures optional behavior of a Workflow created with NewWorkflow
+type WorkflowOption func(*Workflow)
+
+// WithCodec sets the Codec used to serialize the results of the steps of a Workflow. Defaults to GobCodec.
+func WithCodec(codec Codec) WorkflowOption {
+	return func(w *Workflow) {
+		w.codec = codec
+	}
+}
+
+type durableStep struct {
+	name string
+	fn   DurableFunc
+}
+
+// Workflow executes a series of steps with Pipe and checkpoints the result of each step to a CheckpointStore so that a run that is interrupted resumes after its last completed step
+type Workflow struct {
+	name  string
+	store CheckpointStore
+	codec Codec
+	steps []durableStep
+}
+
+// NewWorkflow creates a Workflow that saves its checkpoints to store. The name is part of every checkpoint key and idempotency key so it should be unique for each Workflow sharing a store.
+func NewWorkflow(name string, store CheckpointStore, opts ...WorkflowOption) *Workflow {
+	w := &Workflow{name: name, store: store, codec: GobCodec{}}
+	for _, opt := range opts {
+		opt(w)
+	}
+	return w
+}
+
+// Step adds a named step that executes fn with the result of the previous step. Steps are identified by name so renaming a step causes runs to execute it again.
+func (w *Workflow) Step(name string, fn DurableFunc) *Workflow {
+	w.steps = append(w.steps, durableStep{name, fn})
+	return w
+}
+
+func (w *Workflow) key(runID string, step string) string {
+	return fmt.Sprintf("%s/%s/%s", w.name, runID, step)
+}
+
+// resume returns the index of the first step of the run that has not completed along with the result of the step before it
+func (w *Workflow) resume(runID string, steps []durableStep, argv interface{}) (int, interface{}, error) {
+	seen := map[string]bool{}
+	for _, step := range steps {
+		if seen[step.name] {
+			return 0, nil, DuplicateStepError{step.name}
+		}
+		seen[step.name] = true
+	}
+	for i, step := range steps {
+		data, ok, err := w.store.Load(w.key(runID, step.name))
+		if err != nil {
+			return 0, nil, CheckpointError{step.name, err}
+		}
+		if !ok {
+			return i, argv, nil
+		}
+		if argv, err = w.codec.Decode(data); err != nil {
+			return 0, nil, CheckpointError{step.name, err}
+		}
+	}
+	return len(steps), argv, nil
+}
+
+// checkpoint wraps a step in a ThenableFunc that saves its result. The decoded result is passed to the next step so that a run behaves the same whether or not it was resumed.
+func (w *Workflow) checkpoint(runID string, step durableStep) ThenableFunc {
+	key := w.key(runID, step.name)
+	return func(argv interface{}) (interface{}, error) {
+		result, err := step.fn(key, argv)
+		if err != nil {
+			return nil, err
+		}
+		data, err := w.codec.Encode(result)
+		if err != nil {
+			return nil, CheckpointError{step.name, err}
+		}
+		if err := w.store.Save(key, data); err != nil {
+			return nil, CheckpointError{step.name, err}
+		}
+		if result, err = w.codec.Decode(data); err != nil {
+			return nil, CheckpointError{step.name, err}
+		}
+		return result, nil
+	}
+}
+
+// Run executes the steps of the run identified by runID starting with argv and returns a Future that resolves with the result of the last step. When the run was interrupted before, the completed
+// steps are skipped and the run resumes with the checkpointed result of the last completed step. Running a completed run resolves with its checkpointed result without executing any step.
+// A run must not be executed by more than one process at a time.
+func (w *Workflow) Run(runID string, argv interface{}) Future {
+	steps := append([]durableStep{}, w.steps...)
+	return NewFuture(func() (interface{}, error) {
+		start, v, err := w.resume(runID, steps, argv)
+		if err != nil {
+			return nil, err
+		}
+		fns := make([]ThenableFunc, 0, len(steps)-start)
+		for _, step := range steps[start:] {
+			fns = append(fns, w.checkpoint(runID, step))
+		}
+		value := <-Pipe(fns...)(v)
+		return value.Data, value.Error
+	})
+}
+
+// Forget deletes the checkpoints of the run identified by runID so that running it again starts from the first step
+func (w *Workflow) Forget(runID string) error {
+	for _, step := range w.steps {
+		if err := w.store.Delete(w.key(runID, step.name)); err != nil {
+			return CheckpointError{step.name, err}
+		}
+	}
+	return nil
+}
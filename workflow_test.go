@@ -0,0 +1,92 @@
+package futures
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkflow(t *testing.T) {
+	dir, err := ioutil.TempDir("", "futures-workflow")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	calls := map[string]int{}
+	var keys []string
+	crash := true
+	newWorkflow := func() *Workflow {
+		store, err := NewFileStore(dir)
+		assert.NoError(t, err)
+		return NewWorkflow("order", store).
+			Step("reserve", func(key string, v interface{}) (interface{}, error) {
+				calls["reserve"]++
+				keys = append(keys, key)
+				return v.(int) + 1, nil
+			}).
+			Step("charge", func(key string, v interface{}) (interface{}, error) {
+				calls["charge"]++
+				keys = append(keys, key)
+				if crash {
+					return nil, fmt.Errorf("process crashed")
+				}
+				return v.(int) * 10, nil
+			}).
+			Step("ship", func(key string, v interface{}) (interface{}, error) {
+				calls["ship"]++
+				return fmt.Sprint("shipped ", v), nil
+			})
+	}
+
+	value := <-newWorkflow().Run("run-1", 1)
+	assert.EqualError(t, value.Error, "process crashed")
+
+	crash = false
+	value = <-newWorkflow().Run("run-1", 1)
+	assert.NoError(t, value.Error)
+	assert.Equal(t, "shipped 20", value.Data, "should resume with the checkpointed result of the last completed step")
+	assert.Equal(t, map[string]int{"reserve": 1, "charge": 2, "ship": 1}, calls, "should not execute completed steps again")
+	assert.Equal(t, []string{"order/run-1/reserve", "order/run-1/charge", "order/run-1/charge"}, keys, "should pass the same idempotency key every time a step is executed")
+
+	value = <-newWorkflow().Run("run-1", 1)
+	assert.Equal(t, "shipped 20", value.Data, "should resolve a completed run with its checkpointed result")
+	assert.Equal(t, 1, calls["ship"], "should not execute the steps of a completed run")
+
+	value = <-newWorkflow().Run("run-2", 5)
+	assert.Equal(t, "shipped 60", value.Data, "should checkpoint each run separately")
+
+	w := newWorkflow()
+	assert.NoError(t, w.Forget("run-1"))
+	<-w.Run("run-1", 1)
+	assert.Equal(t, 3, calls["reserve"], "should start from the first step once the checkpoints are forgotten")
+
+	store := NewMemoryStore()
+	value = <-NewWorkflow("json", store, WithCodec(JSONCodec{})).
+		Step("count", func(key string, v interface{}) (interface{}, error) {
+			return 1, nil
+		}).
+		Step("next", func(key string, v interface{}) (interface{}, error) {
+			return v, nil
+		}).
+		Run("run", nil)
+	assert.Equal(t, float64(1), value.Data, "should pass the serialized result to the next step")
+
+	value = <-NewWorkflow("unserializable", store).
+		Step("func", func(key string, v interface{}) (interface{}, error) {
+			return func() {}, nil
+		}).
+		Run("run", nil)
+	assert.IsType(t, CheckpointError{}, value.Error, "should fail a step whose result cannot be serialized")
+
+	value = <-NewWorkflow("duplicate", store).
+		Step("a", func(key string, v interface{}) (interface{}, error) {
+			return nil, nil
+		}).
+		Step("a", func(key string, v interface{}) (interface{}, error) {
+			return nil, nil
+		}).
+		Run("run", nil)
+	assert.Equal(t, DuplicateStepError{"a"}, value.Error, "should reject steps with the same name")
+}